@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sunny/pmt/internal/clipboard"
+	"github.com/sunny/pmt/internal/config"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/ui"
+	"github.com/sunny/pmt/internal/utils"
+)
+
+// pickerActions returns the keys available in the apply selector: edit,
+// delete, move, copy and duplicate the highlighted prompt without leaving it
+func pickerActions(store storage.Store, backend clipboard.Backend, opts renderOptions) []ui.Action {
+	return []ui.Action{
+		{
+			Key:     'e',
+			Label:   "Edit",
+			Suspend: true,
+			Run: func(p *models.Prompt, _ string) (string, error) {
+				return editPrompt(store, p)
+			},
+		},
+		{
+			Key:     'd',
+			Label:   "Delete",
+			Confirm: true,
+			Run: func(p *models.Prompt, _ string) (string, error) {
+				if err := store.Delete(p.ID); err != nil {
+					return "", fmt.Errorf("failed to delete prompt: %w", err)
+				}
+				return fmt.Sprintf("✓ Deleted prompt: %s", p.ID), nil
+			},
+		},
+		{
+			Key:   'm',
+			Label: "Move",
+			Input: "Move to context",
+			Default: func(p *models.Prompt) string {
+				return p.Context
+			},
+			Run: func(p *models.Prompt, context string) (string, error) {
+				context = models.NormalizeContext(context)
+				promptStore, err := store.LoadAll()
+				if err != nil {
+					return "", fmt.Errorf("failed to load prompts: %w", err)
+				}
+				if err := checkArchived(promptStore, p.Project, context); err != nil {
+					return "", err
+				}
+				if err := store.Update(p.ID, func(p *models.Prompt) {
+					p.Context = context
+				}); err != nil {
+					return "", fmt.Errorf("failed to update prompt: %w", err)
+				}
+				if context == "" {
+					context = "(no context)"
+				}
+				return fmt.Sprintf("✓ Moved %s to %s", p.ID, context), nil
+			},
+		},
+		{
+			Key:   'y',
+			Label: "Copy",
+			Run: func(p *models.Prompt, _ string) (string, error) {
+				ropts := opts
+				if ropts.Variant != "" {
+					ok, err := hasVariant(store, p, ropts.Variant)
+					if err != nil {
+						return "", err
+					}
+					if !ok {
+						ropts.Variant = ""
+					}
+				}
+				content, err := renderContent(store, p, ropts)
+				if err != nil {
+					return "", err
+				}
+				if err := copyText(backend, content); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("✓ Copied to clipboard (%s): %s", backend.Name(), p.ID), nil
+			},
+		},
+		{
+			Key:   'c',
+			Label: "Duplicate",
+			Run: func(p *models.Prompt, _ string) (string, error) {
+				return duplicatePrompt(store, p)
+			},
+		},
+	}
+}
+
+// editPrompt opens a prompt's content in the editor and saves the result
+func editPrompt(store storage.Store, p *models.Prompt) (string, error) {
+	// Chat prompts are edited in their text form, one role marker per turn
+	edited, err := editText(p.Text())
+	if err != nil {
+		return "", fmt.Errorf("failed to open editor: %w", err)
+	}
+
+	content := strings.TrimSpace(edited)
+	if content == strings.TrimSpace(p.Text()) {
+		return "No changes", nil
+	}
+	if content == "" && p.Extends == "" {
+		return "", fmt.Errorf("prompt content cannot be empty")
+	}
+
+	var messages []models.Message
+	if p.IsChat() {
+		messages, err = models.ParseMessages(content)
+		if err != nil {
+			return "", err
+		}
+		content = ""
+	}
+
+	if err := store.Update(p.ID, func(p *models.Prompt) {
+		p.Content = content
+		p.Messages = messages
+	}); err != nil {
+		return "", fmt.Errorf("failed to update prompt: %w", err)
+	}
+	return fmt.Sprintf("✓ Updated prompt: %s", p.ID), nil
+}
+
+// duplicatePrompt saves a copy of a prompt under a new ID
+func duplicatePrompt(store storage.Store, p *models.Prompt) (string, error) {
+	dup := *p
+	dup.ID = utils.GenerateID()
+	dup.CreatedAt = time.Now()
+	dup.Annotations = nil
+	if p.Name != "" {
+		// Names are unique references, so the copy needs its own
+		promptStore, err := store.LoadAll()
+		if err != nil {
+			return "", fmt.Errorf("failed to load prompts: %w", err)
+		}
+		dup.Name = copyName(promptStore, p.Name)
+	}
+	dup.Tags = append([]string(nil), p.Tags...)
+	dup.Messages = append([]models.Message(nil), p.Messages...)
+	dup.Vars = copyMap(p.Vars)
+	dup.Variants = copyMap(p.Variants)
+
+	if err := store.Save(&dup); err != nil {
+		return "", fmt.Errorf("failed to save prompt: %w", err)
+	}
+	if dup.Name != "" {
+		return fmt.Sprintf("✓ Duplicated %s as %s [%s]", p.ID, dup.ID, dup.Name), nil
+	}
+	return fmt.Sprintf("✓ Duplicated %s as %s", p.ID, dup.ID), nil
+}
+
+// copyName returns the first of name-copy, name-copy-2, ... that no prompt,
+// including trashed ones, uses yet; names are compared case-insensitively
+func copyName(promptStore *models.PromptStore, name string) string {
+	taken := make(map[string]bool)
+	for _, prompts := range [][]models.Prompt{promptStore.Prompts, promptStore.Trash} {
+		for _, p := range prompts {
+			taken[strings.ToLower(p.Name)] = true
+		}
+	}
+
+	candidate := name + "-copy"
+	for n := 2; taken[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s-copy-%d", name, n)
+	}
+	return candidate
+}
+
+// copyMap returns a shallow copy of m, or nil when it is empty
+func copyMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// createPrompt writes a new prompt in the editor and saves it in the given context
+func createPrompt(store storage.Store, context string) (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return "", fmt.Errorf("failed to load prompts: %w", err)
+	}
+	promptType, err := validateType(promptStore, cfg.PromptType())
+	if err != nil {
+		return "", err
+	}
+
+	project := currentProject()
+	prompt := &models.Prompt{
+		ID:          utils.GenerateID(),
+		Type:        promptType,
+		Project:     project.ID,
+		ProjectName: project.Name,
+		Context:     context,
+		Tags:        []string{},
+		CreatedAt:   time.Now(),
+	}
+	if err := applyContextDefaults(store, prompt, false); err != nil {
+		return "", err
+	}
+
+	prompt.Content, err = openEditor(templateFor(promptStore, prompt.Type))
+	if err != nil {
+		return "", fmt.Errorf("failed to open editor: %w", err)
+	}
+	if prompt.Content == "" {
+		return "Empty prompt, nothing saved", nil
+	}
+	if err := store.Save(prompt); err != nil {
+		return "", fmt.Errorf("failed to save prompt: %w", err)
+	}
+	return fmt.Sprintf("✓ Saved prompt: %s", prompt.ID), nil
+}
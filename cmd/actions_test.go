@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+func TestCopyName(t *testing.T) {
+	s := &models.PromptStore{
+		Prompts: []models.Prompt{
+			{Name: "review"},
+			{Name: "Review-Copy"},
+			{Name: "deploy"},
+			{Name: "fix"},
+			{Name: "fix-copy"},
+		},
+		Trash: []models.Prompt{{Name: "fix-copy-2"}},
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"deploy", "deploy-copy"},
+		{"review", "review-copy-2"},
+		{"fix", "fix-copy-3"},
+		{"fix-copy", "fix-copy-copy"},
+	}
+	for _, tt := range tests {
+		if got := copyName(s, tt.name); got != tt.want {
+			t.Errorf("copyName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,44 +2,166 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/render"
 	"github.com/sunny/pmt/internal/storage"
 	"github.com/sunny/pmt/internal/ui"
 )
 
 var (
-	applyContext string
+	applyContext    string
+	applyAll        bool
+	applyBranch     string
+	applyAnyBranch  bool
+	applyMaxBytes   int
+	applyVariant    string
+	applyVars       map[string]string
+	applyFormat     string
+	applyModel      string
+	applyMaxTokens  int
+	applyTo         string
+	applyClearAfter time.Duration
+	applyMulti      bool
+	applySeparator  string
 )
 
 var applyCmd = &cobra.Command{
-	Use:   "apply",
+	Use:   "apply [id|name|index]",
 	Short: "Select and copy a prompt to clipboard",
 	Long: `Interactively select a prompt from your saved prompts.
 
 The selected prompt will be copied to your clipboard automatically.
 Use arrow keys to navigate and press Enter to select.
-Press / to search.`,
+Press / to search.
+
+The selector is also where prompts are managed. These keys act on the
+highlighted prompt and refresh the list in place:
+  e   edit the content in $EDITOR
+  d   delete (asks for confirmation)
+  m   move to another context
+  y   copy without leaving the selector
+  c   duplicate
+
+Inside a subdirectory of the repository, prompts from the context inferred
+from it (see 'pmt push') are listed first, unless -c is given.
+
+Only prompts from the current git project and global prompts are offered.
+Use --all to include every project, or set 'scope: all' in ~/.pmt/config.yaml
+to make that the default.
+
+Like 'git stash', prompts are also tied to the branch they were pushed on:
+only those from the current branch, and those not tied to any branch, are
+offered. Use --branch to look at another branch, or --any-branch for all.
+
+To skip the selector, pass a full or prefix ID, a prompt name, or a
+git-stash-style index: 0 (or stash@{0}) is the most recent prompt in the
+current project, 1 the one before it, and so on.
+
+With --multi, mark several prompts with space and confirm with enter. They are
+copied in the order they were marked, joined with --separator (or merged into
+one request body with --as). References can also be passed as arguments.
+
+File placeholders in the prompt are expanded relative to the repository root
+before copying, turning a saved prompt into a reusable context pack:
+  {{file:main.go}}              the whole file
+  {{file:main.go#L10-40}}       only lines 10 to 40
+  {{file:internal/**/*.go}}     every matching file not ignored by .gitignore
+
+Other prompts can be included by name or ID with {{> style-guide}}.
+Includes are resolved recursively at apply time, so edits to the included
+prompt are picked up everywhere it is used.
+
+If the selected prompt has variants, you will be asked which one to copy
+unless --variant is given. Use --var to set {{var:name}} placeholders.
+
+Use --as to copy the prompt as a chat API request body instead of plain text.
+Chat prompts keep their system, user and assistant turns; other prompts become
+a single user message.
+
+The clipboard backend is detected automatically: wl-copy, xclip or xsel when a
+display is available, otherwise an OSC 52 escape sequence to the terminal
+(which works over SSH), the tmux buffer, or stdout. Override it with --to
+or the 'clipboard' setting in ~/.pmt/config.yaml.
+
+With --clear-after, a background process puts the previous clipboard contents
+back after the timeout, unless something else was copied in the meantime.`,
 	Example: `  pmt apply
-  pmt apply -c backend`,
+  pmt apply a7f
+  pmt apply style-guide
+  pmt apply 0
+  pmt apply -c backend
+  pmt apply --all
+  pmt apply --any-branch
+  pmt apply -m
+  pmt apply -m style-guide task constraints --separator '\n---\n'
+  pmt apply --variant terse
+  pmt apply --var lang=go --var module=storage
+  pmt apply --max-bytes 524288
+  pmt apply --as openai-json --model gpt-4o
+  pmt apply --to osc52
+  pmt apply --to stdout | less
+  pmt apply --clear-after 45s
+  pmt apply --as anthropic-json --model claude-sonnet-4-5 --max-tokens 2048`,
 	RunE: runApply,
 }
 
 func init() {
 	rootCmd.AddCommand(applyCmd)
 	applyCmd.Flags().StringVarP(&applyContext, "context", "c", "", "Filter by context")
+	applyCmd.Flags().BoolVarP(&applyAll, "all", "a", false, "Include prompts from every project, not only the current one")
+	applyCmd.Flags().StringVarP(&applyBranch, "branch", "b", "", "Offer prompts pushed on this branch (default: the current branch)")
+	applyCmd.Flags().BoolVar(&applyAnyBranch, "any-branch", false, "Offer prompts from every branch")
+	applyCmd.Flags().IntVar(&applyMaxBytes, "max-bytes", render.DefaultMaxBytes, "Total byte budget for {{file:...}} includes")
+	applyCmd.Flags().StringVar(&applyVariant, "variant", "", "Variant of the prompt to copy")
+	applyCmd.Flags().StringToStringVar(&applyVars, "var", nil, "Set a template variable (name=value)")
+	applyCmd.Flags().StringVar(&applyFormat, "as", render.FormatPlain, "Output format: plain, openai-json, anthropic-json")
+	applyCmd.Flags().StringVar(&applyModel, "model", "", "Model name for JSON request bodies")
+	applyCmd.Flags().IntVar(&applyMaxTokens, "max-tokens", render.DefaultMaxTokens, "max_tokens for anthropic-json")
+	applyCmd.Flags().StringVar(&applyTo, "to", "", "Clipboard backend: auto, system, xclip, xsel, wl-copy, pbcopy, osc52, tmux, stdout, file:<path>")
+	applyCmd.Flags().BoolVarP(&applyMulti, "multi", "m", false, "Select several prompts and copy them joined together")
+	applyCmd.Flags().StringVar(&applySeparator, "separator", `\n\n`, "Separator between prompts copied with --multi")
+	applyCmd.Flags().DurationVar(&applyClearAfter, "clear-after", 0, "Restore the previous clipboard contents after this long (e.g. 45s)")
 }
 
 func runApply(cmd *cobra.Command, args []string) error {
+	switch applyFormat {
+	case render.FormatPlain, render.FormatOpenAIJSON, render.FormatAnthropicJSON:
+	default:
+		return fmt.Errorf("invalid format: %s (must be plain, openai-json, or anthropic-json)", applyFormat)
+	}
+
+	backend, err := clipboardBackend(applyTo, applyClearAfter > 0)
+	if err != nil {
+		return err
+	}
+
 	store, err := storage.NewFileStore()
 	if err != nil {
 		return fmt.Errorf("failed to create store: %w", err)
 	}
 
-	// Apply filters
+	project, err := currentScope(applyAll)
+	if err != nil {
+		return err
+	}
+
+	// Apply filters; global prompts show up in every project, and prompts
+	// not tied to a branch on every branch
+	branch := currentBranch(project, applyBranch, applyAnyBranch)
 	filterOpts := storage.FilterOptions{
-		Context: applyContext,
+		Project:           project,
+		Context:           applyContext,
+		IncludeGlobal:     true,
+		Branch:            branch,
+		IncludeUnbranched: true,
+	}
+
+	if len(args) == 0 {
+		setTypeColors(store)
 	}
 
 	prompts, err := store.Filter(filterOpts)
@@ -48,22 +170,205 @@ func runApply(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(prompts) == 0 {
-		return fmt.Errorf("no prompts available. Use 'pmt push' to add prompts")
+		return noPromptsError(project, branch)
+	}
+
+	// Prompts of the current directory's context come first
+	preferred := ""
+	if !cmd.Flags().Changed("context") {
+		preferred, err = inferContext()
+		if err != nil {
+			return err
+		}
+	}
+	prompts = preferContext(prompts, preferred)
+
+	ropts := renderOptions{
+		MaxBytes: applyMaxBytes,
+		Variant:  applyVariant,
+		Vars:     applyVars,
+	}
+
+	// Pick by reference, or show the interactive selector with its actions
+	selected, interactive, err := choosePrompts(prompts, args, ui.PickerOptions{
+		Multi:   applyMulti,
+		Actions: pickerActions(store, backend, ropts),
+		Reload: func() ([]models.Prompt, error) {
+			prompts, err := store.Filter(filterOpts)
+			return preferContext(prompts, preferred), err
+		},
+	})
+	if err != nil {
+		return scopeHint(err, project, branch, args)
 	}
 
-	// Show interactive selector
-	selected, err := ui.SelectPrompt(prompts)
+	content, err := buildOutput(store, selected, interactive, outputOptions{
+		Render:    ropts,
+		Format:    applyFormat,
+		Chat:      render.ChatOptions{Model: applyModel, MaxTokens: applyMaxTokens},
+		Separator: applySeparator,
+	})
 	if err != nil {
-		return fmt.Errorf("selection cancelled or failed: %w", err)
+		return err
+	}
+
+	var previous string
+	if applyClearAfter > 0 {
+		previous, err = readPrevious(backend)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Copy to clipboard
-	if err := clipboard.WriteAll(selected.Content); err != nil {
-		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	if err := copyText(backend, content); err != nil {
+		return err
 	}
 
-	fmt.Printf("\n✓ Copied to clipboard: %s\n", selected.ID)
-	fmt.Println("💡 Now paste (Ctrl+V) into Copilot!")
+	if applyClearAfter > 0 {
+		if err := scheduleRestore(backend, previous, content, applyClearAfter); err != nil {
+			return err
+		}
+	}
+
+	out := statusWriter(backend)
+	fmt.Fprintf(out, "\n✓ Copied to clipboard (%s): %s\n", backend.Name(), joinIDs(selected))
+	fmt.Fprintln(out, "💡 Now paste (Ctrl+V) into Copilot!")
+	if applyClearAfter > 0 {
+		fmt.Fprintf(out, "🔒 Clipboard will be restored in %s\n", applyClearAfter)
+	}
 
 	return nil
 }
+
+// outputOptions controls how selected prompts are turned into the copied text
+type outputOptions struct {
+	Render    renderOptions
+	Format    string
+	Chat      render.ChatOptions
+	Separator string
+}
+
+// buildOutput renders the selected prompts and formats them for copying.
+// Several prompts are joined with the separator in plain format, and merged
+// into a single request body in the JSON formats.
+func buildOutput(store storage.Store, selected []*models.Prompt, interactive bool, opts outputOptions) (string, error) {
+	// One renderer for all prompts, so the include budget covers the whole output
+	renderer, err := newRenderer(store, opts.Render)
+	if err != nil {
+		return "", err
+	}
+	return formatOutput(renderer, selected, interactive, opts)
+}
+
+// formatOutput renders the selected prompts with renderer and formats them,
+// see buildOutput
+func formatOutput(renderer *render.Renderer, selected []*models.Prompt, interactive bool, opts outputOptions) (string, error) {
+	var parts []string
+	var messages []models.Message
+
+	for _, p := range selected {
+		variant := opts.Render.Variant
+		if len(selected) > 1 {
+			// A variant only applies to the prompts that define it, or whose bases do
+			if variant != "" {
+				source, err := renderer.VariantSource(p, variant)
+				if err != nil {
+					return "", err
+				}
+				if source == nil {
+					variant = ""
+				}
+			}
+		} else if interactive {
+			chosen, err := chooseVariant(p, variant)
+			if err != nil {
+				return "", err
+			}
+			variant = chosen
+		}
+
+		renderer.Variant = variant
+		msgs, err := renderer.RenderMessages(p)
+		if err != nil {
+			return "", fmt.Errorf("failed to render prompt: %w", err)
+		}
+		messages = append(messages, msgs...)
+
+		part, err := render.FormatChat(msgs, render.FormatPlain, opts.Chat)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, part)
+	}
+
+	if opts.Format == "" || opts.Format == render.FormatPlain {
+		return strings.Join(parts, unescapeSeparator(opts.Separator)), nil
+	}
+	return render.FormatChat(messages, opts.Format, opts.Chat)
+}
+
+// unescapeSeparator interprets \n and \t typed on the command line
+func unescapeSeparator(sep string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(sep)
+}
+
+// renderOptions controls how a prompt is rendered before it is copied
+type renderOptions struct {
+	MaxBytes int
+	Variant  string
+	Vars     map[string]string
+}
+
+// newRenderer creates a renderer that looks partials and bases up in store
+func newRenderer(store storage.Store, opts renderOptions) (*render.Renderer, error) {
+	renderer, err := render.NewRenderer()
+	if err != nil {
+		return nil, err
+	}
+	renderer.MaxBytes = opts.MaxBytes
+	renderer.Variant = opts.Variant
+	renderer.Vars = opts.Vars
+	renderer.Lookup = func(ref string) (*models.Prompt, error) {
+		return findPrompt(store, ref)
+	}
+	return renderer, nil
+}
+
+// hasVariant reports whether a prompt or one of its bases defines a variant
+func hasVariant(store storage.Store, p *models.Prompt, variant string) (bool, error) {
+	renderer, err := newRenderer(store, renderOptions{})
+	if err != nil {
+		return false, err
+	}
+	source, err := renderer.VariantSource(p, variant)
+	return source != nil, err
+}
+
+// renderContent expands placeholders in a prompt's content before it is copied
+func renderContent(store storage.Store, p *models.Prompt, opts renderOptions) (string, error) {
+	renderer, err := newRenderer(store, opts)
+	if err != nil {
+		return "", err
+	}
+
+	rendered, err := renderer.RenderPrompt(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt: %w", err)
+	}
+	return rendered, nil
+}
+
+// renderMessages renders a prompt into the chat turns that are copied or run
+func renderMessages(store storage.Store, p *models.Prompt, opts renderOptions) ([]models.Message, error) {
+	renderer, err := newRenderer(store, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := renderer.RenderMessages(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render prompt: %w", err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/render"
+)
+
+func TestFormatOutputSharesIncludeBudget(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("0123456789\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	selected := []*models.Prompt{
+		{ID: "a", Content: "First {{file:notes.txt}}"},
+		{ID: "b", Content: "Second {{file:notes.txt}}"},
+	}
+
+	tests := []struct {
+		name     string
+		maxBytes int
+		wantErr  bool
+	}{
+		{"both includes fit", 22, false},
+		{"each include fits but not both", 15, true},
+	}
+	for _, tt := range tests {
+		renderer := &render.Renderer{Root: root, MaxBytes: tt.maxBytes}
+		got, err := formatOutput(renderer, selected, false, outputOptions{Separator: `\n`})
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "budget") {
+				t.Errorf("%s: err = %v, want the include budget to be exceeded", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if strings.Count(got, "0123456789") != 2 {
+			t.Errorf("%s: output = %q, want both includes", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+)
+
+var (
+	archiveUnset bool
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <id>",
+	Short: "Hide a prompt without deleting it",
+	Long: `Archive a prompt, so apply, pop and list no longer offer it. Archived
+prompts are listed with 'pmt list --archived'.
+
+'pmt prune --merged' archives the prompts of merged branches. Use --unset to
+bring an archived prompt back.`,
+	Example: `  pmt archive a7f
+  pmt archive a7f --unset`,
+	Args: cobra.ExactArgs(1),
+	RunE: runArchive,
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	archiveCmd.Flags().BoolVar(&archiveUnset, "unset", false, "Bring an archived prompt back")
+}
+
+func runArchive(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	prompt, err := findPrompt(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	err = store.Update(prompt.ID, func(p *models.Prompt) {
+		p.Archived = !archiveUnset
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update prompt: %w", err)
+	}
+
+	if archiveUnset {
+		fmt.Printf("✓ Prompt %s is no longer archived\n", prompt.ID)
+	} else {
+		fmt.Printf("✓ Archived prompt: %s\n", prompt.ID)
+	}
+	return nil
+}
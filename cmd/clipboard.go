@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/clipboard"
+	"github.com/sunny/pmt/internal/config"
+)
+
+// clipboardBackend returns the backend named by --to, falling back to the
+// configured backend and finally to auto-detection. With readable set, for
+// --clear-after, the backend must be able to read the clipboard back, and
+// auto-detection only considers such backends.
+func clipboardBackend(to string, readable bool) (clipboard.Backend, error) {
+	if to == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		to = cfg.Clipboard
+	}
+
+	if readable && (to == "" || to == "auto") {
+		backend := clipboard.DetectReader()
+		if backend == nil {
+			return nil, fmt.Errorf("--clear-after needs a clipboard that can be read back, and none was found (osc52 and stdout cannot be read); install xclip, xsel or wl-clipboard, or run inside tmux")
+		}
+		return backend, nil
+	}
+
+	backend, err := clipboard.New(to)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := backend.(clipboard.Reader); readable && !ok {
+		return nil, fmt.Errorf("--clear-after needs a clipboard backend that can be read back; %s cannot (try --to tmux, xclip, xsel, wl-copy or pbcopy)", backend.Name())
+	}
+	return backend, nil
+}
+
+// copyText copies text with the given clipboard backend
+func copyText(backend clipboard.Backend, text string) error {
+	if err := backend.Write(text); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+	return nil
+}
+
+// statusWriter returns where progress messages go: stderr when the prompt
+// itself was written to stdout, so pipes only receive the prompt
+func statusWriter(backend clipboard.Backend) io.Writer {
+	if clipboard.IsStdout(backend) {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// restoreRequest is handed to the background restore process on stdin
+type restoreRequest struct {
+	Previous   string `json:"previous"`
+	CopiedHash string `json:"copied_hash"`
+}
+
+var (
+	restoreAfter time.Duration
+	restoreTo    string
+)
+
+var clipboardRestoreCmd = &cobra.Command{
+	Use:    "clipboard-restore",
+	Short:  "Restore the previous clipboard contents (used by --clear-after)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runClipboardRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(clipboardRestoreCmd)
+	clipboardRestoreCmd.Flags().DurationVar(&restoreAfter, "after", 45*time.Second, "Delay before restoring")
+	clipboardRestoreCmd.Flags().StringVar(&restoreTo, "to", "", "Clipboard backend")
+}
+
+// readPrevious reads the clipboard before it is overwritten, so --clear-after can restore it
+func readPrevious(backend clipboard.Backend) (string, error) {
+	reader, ok := backend.(clipboard.Reader)
+	if !ok {
+		return "", fmt.Errorf("--clear-after needs a clipboard backend that can be read back; %s cannot", backend.Name())
+	}
+
+	previous, err := reader.Read()
+	if err != nil {
+		return "", fmt.Errorf("failed to read current clipboard: %w", err)
+	}
+	return previous, nil
+}
+
+// scheduleRestore forks a background process that puts previous back on the
+// clipboard after the delay, unless the clipboard no longer holds copied
+func scheduleRestore(backend clipboard.Backend, previous, copied string, after time.Duration) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate pmt executable: %w", err)
+	}
+
+	payload, err := json.Marshal(restoreRequest{Previous: previous, CopiedHash: clipboardHash(copied)})
+	if err != nil {
+		return fmt.Errorf("failed to prepare clipboard restore: %w", err)
+	}
+
+	c := exec.Command(self, "clipboard-restore", "--after", after.String(), "--to", backend.Name())
+	detach(c)
+
+	// Write the request ourselves rather than via c.Stdin, whose copying
+	// goroutine would not finish before pmt exits
+	stdin, err := c.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start clipboard restore: %w", err)
+	}
+	if err := c.Start(); err != nil {
+		return fmt.Errorf("failed to start clipboard restore: %w", err)
+	}
+	if _, err := stdin.Write(payload); err != nil {
+		return fmt.Errorf("failed to start clipboard restore: %w", err)
+	}
+	if err := stdin.Close(); err != nil {
+		return fmt.Errorf("failed to start clipboard restore: %w", err)
+	}
+	return c.Process.Release()
+}
+
+func runClipboardRestore(cmd *cobra.Command, args []string) error {
+	var req restoreRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		return fmt.Errorf("failed to read restore request: %w", err)
+	}
+
+	backend, err := clipboard.New(restoreTo)
+	if err != nil {
+		return err
+	}
+	reader, ok := backend.(clipboard.Reader)
+	if !ok {
+		return fmt.Errorf("clipboard backend %s cannot be read back", backend.Name())
+	}
+
+	time.Sleep(restoreAfter)
+
+	// Leave the clipboard alone if something else was copied in the meantime
+	current, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	if clipboardHash(current) != req.CopiedHash {
+		return nil
+	}
+
+	return backend.Write(req.Previous)
+}
+
+// clipboardHash fingerprints clipboard contents, ignoring the trailing
+// newline some clipboard tools add or strip
+func clipboardHash(text string) string {
+	sum := sha256.Sum256([]byte(strings.TrimRight(text, "\r\n")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/config"
+	"github.com/sunny/pmt/internal/storage"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change settings",
+	Long: `Show and change the settings in ~/.pmt/config.yaml.
+
+From highest to lowest precedence, a setting comes from:
+
+  1. a command line flag, e.g. --profile or 'pmt list --columns'
+  2. its environment variable, e.g. PMT_EDITOR or PMT_PAGE_SIZE
+  3. the repository's .pmt.yaml, inside a git repository; editor, runner,
+     clipboard, store and profile cannot be set there
+  4. the user config file, ~/.pmt/config.yaml
+  5. its default
+
+Values are validated when they are set and whenever the file is read.`,
+	Example: `  pmt config list
+  pmt config get editor
+  pmt config set editor "code --wait"
+  pmt config set list_columns id,name,tags,content
+  pmt config set profile ""       # Unset
+  pmt config edit`,
+}
+
+var configListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List settings with their values and where they come from",
+	Long: `List every setting with its effective value and where that value comes
+from: a flag, an environment variable, the repository's .pmt.yaml, the user
+config file, or the default, followed by the prompt stores in use.`,
+	Example: `  pmt config list`,
+	Args:    cobra.NoArgs,
+	RunE:    runConfigList,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Long: `Print the effective value of a setting, after applying flags, environment
+variables and the repository's .pmt.yaml.`,
+	Example: `  pmt config get date_format
+  PMT_PAGE_SIZE=20 pmt config get page_size`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting in the user config file",
+	Long: `Validate a value and save it in ~/.pmt/config.yaml. An empty value removes
+the setting, so that its default applies again.`,
+	Example: `  pmt config set default_type feature
+  pmt config set color off
+  pmt config set date_format "Jan 2 15:04"
+  pmt config set editor ""`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the user config file in your editor",
+	Long: `Open ~/.pmt/config.yaml in your editor. The file is saved only if it is
+valid; otherwise the error is shown and the file is left unchanged.`,
+	Example: `  pmt config edit`,
+	Args:    cobra.NoArgs,
+	RunE:    runConfigEdit,
+}
+
+func init() {
+	// List the settings in the help, from the registry
+	var settings strings.Builder
+	for _, setting := range config.Settings {
+		fmt.Fprintf(&settings, "\n  %-14s %s", setting.Key, setting.Usage)
+		if setting.Default != "" {
+			fmt.Fprintf(&settings, " (default: %s)", setting.Default)
+		}
+	}
+	configCmd.Long += "\n\nSettings:" + settings.String()
+
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configEditCmd)
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	fmt.Printf("%-14s %-30s %s\n", "Key", "Value", "Source")
+	for i := range config.Settings {
+		setting := &config.Settings[i]
+		value, source, err := setting.Resolve()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-14s %-30s %s\n", setting.Key, orDash(value), source)
+	}
+
+	// The prompt store follows from the profile and the repository's prompt_dir
+	profile, err := config.LookupSetting("profile")
+	if err != nil {
+		return err
+	}
+	name, _, err := profile.Resolve()
+	if err != nil {
+		return err
+	}
+	paths, err := storage.Paths(name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nPrompt store:      %s\n", paths[0])
+	if len(paths) > 1 {
+		fmt.Printf("Repository store:  %s (prompt_dir in %s, read on top of the prompt store)\n", paths[1], config.RepoFile)
+	}
+	return nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	setting, err := config.LookupSetting(args[0])
+	if err != nil {
+		return err
+	}
+
+	value, _, err := setting.Resolve()
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, value := args[0], args[1]
+	setting, err := config.LookupSetting(key)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadUser()
+	if err != nil {
+		return fmt.Errorf("%w (run 'pmt config edit' to fix it)", err)
+	}
+	if err := setting.Set(cfg, value); err != nil {
+		return err
+	}
+	if key == "default_type" && cfg.DefaultType != "" {
+		if err := checkDefaultType(cfg.DefaultType); err != nil {
+			return err
+		}
+	}
+	if err := config.SaveUser(cfg); err != nil {
+		return err
+	}
+
+	if value == "" {
+		fmt.Printf("✓ Unset %s\n", key)
+	} else {
+		fmt.Printf("✓ Set %s = %s\n", key, setting.Get(cfg))
+	}
+
+	// Point out a higher-precedence value that hides the one just set
+	if _, source, err := setting.Resolve(); err == nil && source != "user config" && source != "default" {
+		fmt.Printf("💡 %s is currently overridden by %s\n", key, source)
+	}
+	return nil
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	edited, err := editText(string(data))
+	if err != nil {
+		return fmt.Errorf("failed to open editor: %w", err)
+	}
+	if edited == string(data) {
+		fmt.Println("No changes made")
+		return nil
+	}
+
+	if _, err := config.Parse([]byte(edited), path); err != nil {
+		return fmt.Errorf("%w; the file was left unchanged", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	fmt.Printf("✓ Saved %s\n", path)
+	return nil
+}
+
+// checkDefaultType checks that the default type is in the type registry
+func checkDefaultType(name string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+	if err := checkType(store, name); err != nil {
+		return fmt.Errorf("invalid default_type: %w", err)
+	}
+	return nil
+}
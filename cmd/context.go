@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -26,36 +27,71 @@ var contextListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List all contexts",
-	Long:    `List all contexts that have prompts associated with them.`,
+	Long: `List the contexts of the current project that have prompts or were
+created with 'pmt context create'.
+
+Use --project for another project, or --all to list the contexts of every
+project.`,
 	Example: `  pmt context list
-  pmt ctx ls`,
+  pmt ctx ls -p my-api
+  pmt ctx ls --all`,
 	RunE: runContextList,
 }
 
+var (
+	contextListProject string
+	contextListAll     bool
+)
+
+var (
+	contextTreeProject string
+	contextTreeAll     bool
+	contextTreeDepth   int
+	contextTreeType    string
+	contextTreeTags    []string
+	contextTreeAsJSON  bool
+)
+
 var contextTreeCmd = &cobra.Command{
 	Use:   "tree",
 	Short: "Display contexts in a tree structure",
-	Long: `Display all contexts in a hierarchical tree structure.
+	Long: `Display contexts in a hierarchical tree structure.
 
 This shows how contexts are organized with their folder-like paths.
-For example, "backend/api/auth" will be shown as nested folders.`,
+For example, "backend/api/auth" will be shown as nested folders.
+
+Only the current project is shown unless --project or --all is given; with
+--all, each project gets its own tree. Each context shows the prompts directly
+in it, and the total including its sub-contexts when that differs.
+Use --json for a machine-readable tree, e.g. for editor integrations.`,
 	Example: `  pmt context tree
-  pmt ctx tree`,
+  pmt ctx tree --all
+  pmt ctx tree -p my-api --depth 1
+  pmt ctx tree -t bugfix --tag auth
+  pmt ctx tree --json`,
 	RunE: runContextTree,
 }
 
+var contextRenameAll bool
+
 var contextRenameCmd = &cobra.Command{
-	Use:   "rename <old-context> <new-context>",
-	Short: "Rename a context",
-	Long: `Rename a context, updating all prompts that use it.
+	Use:     "rename <old-context> <new-context>",
+	Aliases: []string{"move", "mv"},
+	Short:   "Rename a context",
+	Long: `Rename a context in the current project (or --project), updating all
+prompts that use it.
 
 This command will rename a context and all of its sub-contexts.
 For example, renaming "backend" to "server" will also rename:
   - "backend/api" to "server/api"
-  - "backend/auth" to "server/auth"`,
+  - "backend/auth" to "server/auth"
+
+Other projects keep their contexts unless --all is given. Renaming onto an
+existing context fails; use 'pmt context merge' to combine two contexts.`,
 	Example: `  pmt context rename backend server
-  pmt context rename backend/api backend/rest
-  pmt ctx rename old new`,
+  pmt context rename backend/api backend/rest --dry-run
+  pmt ctx mv old new -p my-api
+  pmt ctx rename old new --all`,
 	Args: cobra.ExactArgs(2),
 	RunE: runContextRename,
 }
@@ -65,6 +101,16 @@ func init() {
 	contextCmd.AddCommand(contextListCmd)
 	contextCmd.AddCommand(contextTreeCmd)
 	contextCmd.AddCommand(contextRenameCmd)
+
+	contextListCmd.Flags().StringVarP(&contextListProject, "project", "p", "", "List the contexts of this project")
+	contextListCmd.Flags().BoolVarP(&contextListAll, "all", "a", false, "List the contexts of every project")
+	contextTreeCmd.Flags().StringVarP(&contextTreeProject, "project", "p", "", "Show the tree of this project")
+	contextTreeCmd.Flags().BoolVarP(&contextTreeAll, "all", "a", false, "Show a tree for every project")
+	contextTreeCmd.Flags().IntVar(&contextTreeDepth, "depth", 0, "Only show contexts up to this depth (0 for no limit)")
+	contextTreeCmd.Flags().StringVarP(&contextTreeType, "type", "t", "", "Only count prompts of this type")
+	contextTreeCmd.Flags().StringSliceVar(&contextTreeTags, "tag", nil, "Only count prompts with these tags")
+	contextTreeCmd.Flags().BoolVar(&contextTreeAsJSON, "json", false, "Print the tree as JSON")
+	contextRenameCmd.Flags().BoolVarP(&contextRenameAll, "all", "a", false, "Rename the context in every project")
 }
 
 func runContextList(cmd *cobra.Command, args []string) error {
@@ -73,58 +119,146 @@ func runContextList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create store: %w", err)
 	}
 
+	// Restrict to one project unless --all (or 'scope: all') is used
+	project, err := listingScope(store, contextListProject, contextListAll)
+	if err != nil {
+		return err
+	}
+
 	// Load all prompts
 	promptStore, err := store.LoadAll()
 	if err != nil {
 		return fmt.Errorf("failed to load prompts: %w", err)
 	}
 
-	if len(promptStore.Prompts) == 0 {
-		fmt.Println("No prompts found. Use 'pmt push' to add prompts.")
-		return nil
+	// Collect the contexts of each project with counts; contexts created with
+	// 'pmt context create' are listed even when empty
+	type contextRow struct {
+		project string
+		path    string
+		count   int
+		record  *models.Context
 	}
-
-	// Collect unique contexts with counts
-	contextCounts := make(map[string]int)
-	for _, p := range promptStore.Prompts {
-		context := p.Context
+	rows := make(map[[2]string]*contextRow)
+	row := func(projectID, path string) *contextRow {
+		key := [2]string{projectID, path}
+		if rows[key] == nil {
+			rows[key] = &contextRow{project: projectID, path: path}
+		}
+		return rows[key]
+	}
+	for i := range promptStore.Prompts {
+		p := &promptStore.Prompts[i]
+		if project != "" && !p.InProject(project) {
+			continue
+		}
+		context := models.NormalizeContext(p.Context)
 		if context == "" {
 			context = "(default)"
 		}
-		contextCounts[context]++
+		row(p.Project, context).count++
+	}
+	for i := range promptStore.Contexts {
+		c := &promptStore.Contexts[i]
+		if project != "" && !strings.EqualFold(c.Project, project) {
+			continue
+		}
+		row(c.Project, c.Path).record = c
 	}
 
-	if len(contextCounts) == 0 {
-		fmt.Println("No contexts found.")
+	if len(rows) == 0 {
+		if project != "" {
+			fmt.Printf("No contexts found in project %s. Use --all to show every project.\n", scopeName(project))
+			return nil
+		}
+		fmt.Println("No contexts found. Use 'pmt push -c <context>' to organize prompts.")
 		return nil
 	}
 
-	// Sort contexts alphabetically
-	contexts := make([]string, 0, len(contextCounts))
-	for ctx := range contextCounts {
-		contexts = append(contexts, ctx)
+	// Sort by project, then alphabetically by context
+	names := projectNames(promptStore)
+	sorted := make([]*contextRow, 0, len(rows))
+	for _, r := range rows {
+		sorted = append(sorted, r)
 	}
-	sort.Strings(contexts)
+	sort.Slice(sorted, func(i, j int) bool {
+		if names[sorted[i].project] != names[sorted[j].project] {
+			return names[sorted[i].project] < names[sorted[j].project]
+		}
+		if sorted[i].project != sorted[j].project {
+			return sorted[i].project < sorted[j].project
+		}
+		return sorted[i].path < sorted[j].path
+	})
 
-	// Print header
-	fmt.Printf("%-20s %s\n", "Context", "Prompts")
-	fmt.Println(strings.Repeat("-", 35))
+	// Print header; the project column is only needed across projects
+	if project == "" {
+		fmt.Printf("%-20s %-20s %-8s %s\n", "Project", "Context", "Prompts", "Description")
+		fmt.Println(strings.Repeat("-", 81))
+	} else {
+		fmt.Printf("%-20s %-8s %s\n", "Context", "Prompts", "Description")
+		fmt.Println(strings.Repeat("-", 60))
+	}
 
 	// Print each context
-	for _, ctx := range contexts {
-		count := contextCounts[ctx]
-		fmt.Printf("%-20s %d\n", ctx, count)
+	for _, r := range sorted {
+		description := ""
+		if r.record != nil {
+			description = r.record.Description
+			if r.record.Archived {
+				description = strings.TrimSpace("(archived) " + description)
+			}
+		}
+		if project == "" {
+			fmt.Printf("%-20s ", truncateString(names[r.project], 20))
+		}
+		fmt.Printf("%-20s %-8d %s\n", r.path, r.count, truncateString(description, 40))
 	}
 
-	fmt.Printf("\nTotal: %d context(s)\n", len(contexts))
+	fmt.Printf("\nTotal: %d context(s)\n", len(sorted))
 	return nil
 }
 
-// TreeNode represents a node in the context tree
-type TreeNode struct {
-	Name     string
-	Prompts  int
-	Children map[string]*TreeNode
+// listingScope returns the project that context list and tree show: the
+// given --project, the current project, or "" for every project
+func listingScope(store storage.Store, ref string, all bool) (string, error) {
+	if ref != "" {
+		return projectFilter(store, ref)
+	}
+	return currentScope(all)
+}
+
+// projectNames maps the ID of each project in the store to its display name
+func projectNames(promptStore *models.PromptStore) map[string]string {
+	names := make(map[string]string)
+	for i := range promptStore.Contexts {
+		names[promptStore.Contexts[i].Project] = promptStore.Contexts[i].Project
+	}
+	for i := range promptStore.Prompts {
+		names[promptStore.Prompts[i].Project] = promptStore.Prompts[i].DisplayProject()
+	}
+	if current := currentProject(); names[current.ID] != "" {
+		names[current.ID] = current.Name
+	}
+	return names
+}
+
+// contextTreeJSON is the --json form of one project's context tree
+type contextTreeJSON struct {
+	Project   string            `json:"project"`
+	ProjectID string            `json:"project_id"`
+	NoContext int               `json:"no_context"`
+	Total     int               `json:"total"`
+	Contexts  []contextNodeJSON `json:"contexts"`
+}
+
+// contextNodeJSON is one context in contextTreeJSON
+type contextNodeJSON struct {
+	Name     string            `json:"name"`
+	Path     string            `json:"path"`
+	Direct   int               `json:"direct"`
+	Total    int               `json:"total"`
+	Children []contextNodeJSON `json:"children,omitempty"`
 }
 
 func runContextTree(cmd *cobra.Command, args []string) error {
@@ -132,58 +266,129 @@ func runContextTree(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create store: %w", err)
 	}
+	if err := checkType(store, contextTreeType); err != nil {
+		return err
+	}
 
-	// Load all prompts
-	promptStore, err := store.LoadAll()
+	// Restrict to one project unless --all (or 'scope: all') is used
+	project, err := listingScope(store, contextTreeProject, contextTreeAll)
+	if err != nil {
+		return err
+	}
+
+	prompts, err := store.Filter(storage.FilterOptions{
+		Type:    contextTreeType,
+		Project: project,
+		Tags:    contextTreeTags,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to load prompts: %w", err)
 	}
 
-	if len(promptStore.Prompts) == 0 {
-		fmt.Println("No prompts found. Use 'pmt push' to add prompts.")
-		return nil
+	// Contexts that have a record but no prompts yet
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+	empty := make(map[string][]string)
+	for _, c := range promptStore.Contexts {
+		if !c.Archived && (project == "" || strings.EqualFold(c.Project, project)) {
+			empty[c.Project] = append(empty[c.Project], c.Path)
+		}
 	}
 
-	root := &TreeNode{
-		Name:     "",
-		Children: make(map[string]*TreeNode),
+	// One tree per project with prompts or context records, in display name order
+	byProject := make(map[string][]models.Prompt)
+	var ids []string
+	for _, p := range prompts {
+		if _, ok := byProject[p.Project]; !ok {
+			ids = append(ids, p.Project)
+		}
+		byProject[p.Project] = append(byProject[p.Project], p)
+	}
+	for id := range empty {
+		if _, ok := byProject[id]; !ok {
+			byProject[id] = nil
+			ids = append(ids, id)
+		}
 	}
+	names := projectNames(promptStore)
+	sort.Slice(ids, func(i, j int) bool {
+		if names[ids[i]] != names[ids[j]] {
+			return names[ids[i]] < names[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
 
-	// Count prompts without context
-	noContextCount := 0
+	if contextTreeAsJSON {
+		trees := make([]contextTreeJSON, 0, len(ids))
+		for _, id := range ids {
+			root, noContext := models.BuildContextTree(byProject[id])
+			for _, path := range empty[id] {
+				root.AddContext(path)
+			}
+			trees = append(trees, contextTreeJSON{
+				Project:   names[id],
+				ProjectID: id,
+				NoContext: noContext,
+				Total:     len(byProject[id]),
+				Contexts:  contextNodesJSON(root, 1),
+			})
+		}
 
-	// Build the tree
-	for _, p := range promptStore.Prompts {
-		if p.Context == "" {
-			noContextCount++
-			continue
+		data, err := json.MarshalIndent(trees, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode tree: %w", err)
 		}
+		fmt.Println(string(data))
+		return nil
+	}
 
-		parts := p.GetContextParts()
-		current := root
+	if len(ids) == 0 {
+		if project != "" {
+			fmt.Printf("No prompts found in project %s. Use --all to show every project.\n", scopeName(project))
+			return nil
+		}
+		fmt.Println("No prompts found. Use 'pmt push' to add prompts.")
+		return nil
+	}
 
-		for _, part := range parts {
-			if current.Children[part] == nil {
-				current.Children[part] = &TreeNode{
-					Name:     part,
-					Children: make(map[string]*TreeNode),
-				}
-			}
-			current = current.Children[part]
-			current.Prompts++
+	totalContexts := 0
+	for i, id := range ids {
+		if i > 0 {
+			fmt.Println()
 		}
+		totalContexts += printContextTree(names[id], byProject[id], empty[id])
+	}
+
+	if len(ids) > 1 {
+		fmt.Printf("\nTotal: %d project%s, %d context%s, %d prompt%s\n",
+			len(ids), pluralize(len(ids)),
+			totalContexts, pluralize(totalContexts),
+			len(prompts), pluralize(len(prompts)))
+	}
+
+	return nil
+}
+
+// printContextTree prints the context tree of one project's prompts, plus
+// the given contexts without prompts, and returns the number of contexts in it
+func printContextTree(project string, prompts []models.Prompt, empty []string) int {
+	root, noContextCount := models.BuildContextTree(prompts)
+	for _, path := range empty {
+		root.AddContext(path)
 	}
 
 	// Print the tree
-	var printTree func(node *TreeNode, prefix string, isLast bool, depth int)
-	printTree = func(node *TreeNode, prefix string, isLast bool, depth int) {
+	var printTree func(node *models.TreeNode, prefix string, isLast bool, depth int)
+	printTree = func(node *models.TreeNode, prefix string, isLast bool, depth int) {
 		if depth > 0 {
 			// Print current node
 			connector := "├── "
 			if isLast {
 				connector = "└── "
 			}
-			fmt.Printf("%s%s%s (%d prompt%s)\n", prefix, connector, node.Name, node.Prompts, pluralize(node.Prompts))
+			fmt.Printf("%s%s%s (%s)\n", prefix, connector, node.Name, nodeCounts(node))
 
 			// Update prefix for children
 			if isLast {
@@ -193,29 +398,24 @@ func runContextTree(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		// Sort children for consistent output
-		childNames := make([]string, 0, len(node.Children))
-		for name := range node.Children {
-			childNames = append(childNames, name)
+		if contextTreeDepth > 0 && depth >= contextTreeDepth {
+			return
 		}
-		sort.Strings(childNames)
 
-		// Print children
-		for i, name := range childNames {
-			child := node.Children[name]
-			isLastChild := i == len(childNames)-1
+		// Print children, sorted for consistent output
+		children := node.SortedChildren()
+		for i, child := range children {
+			isLastChild := i == len(children)-1
 			printTree(child, prefix, isLastChild, depth+1)
 		}
 	}
 
-	// Get project name
-	project := "(root)"
-	if len(promptStore.Prompts) > 0 && promptStore.Prompts[0].Project != "" {
-		project = promptStore.Prompts[0].Project
+	if project == "" {
+		project = "(root)"
 	}
 
 	totalContexts := countContexts(root)
-	totalWithContext := len(promptStore.Prompts) - noContextCount
+	totalWithContext := len(prompts) - noContextCount
 
 	if totalContexts == 0 {
 		fmt.Printf("%s/\n", project)
@@ -223,7 +423,7 @@ func runContextTree(cmd *cobra.Command, args []string) error {
 			fmt.Printf("  (no context): %d prompt%s\n", noContextCount, pluralize(noContextCount))
 		}
 		fmt.Println("\nNo contexts found. Use 'pmt push -c <context>' to organize prompts.")
-		return nil
+		return 0
 	}
 
 	fmt.Printf("%s/\n", project)
@@ -237,17 +437,45 @@ func runContextTree(cmd *cobra.Command, args []string) error {
 		totalContexts, pluralize(totalContexts),
 		totalWithContext, pluralize(totalWithContext))
 
-	return nil
+	return totalContexts
+}
+
+// nodeCounts describes a node's prompts, separating those directly in the
+// context from those in its sub-contexts
+func nodeCounts(node *models.TreeNode) string {
+	if node.Direct == node.Prompts {
+		return fmt.Sprintf("%d prompt%s", node.Direct, pluralize(node.Direct))
+	}
+	return fmt.Sprintf("%d prompt%s, %d total", node.Direct, pluralize(node.Direct), node.Prompts)
+}
+
+// contextNodesJSON converts the children of a node for --json, down to --depth
+func contextNodesJSON(node *models.TreeNode, depth int) []contextNodeJSON {
+	children := node.SortedChildren()
+	nodes := make([]contextNodeJSON, 0, len(children))
+	for _, child := range children {
+		n := contextNodeJSON{
+			Name:   child.Name,
+			Path:   child.Path,
+			Direct: child.Direct,
+			Total:  child.Prompts,
+		}
+		if contextTreeDepth == 0 || depth < contextTreeDepth {
+			n.Children = contextNodesJSON(child, depth+1)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
 }
 
 // Helper function to count total contexts in tree
-func countContexts(node *TreeNode) int {
+func countContexts(node *models.TreeNode) int {
 	if node == nil {
 		return 0
 	}
 
 	count := 0
-	if node.Prompts > 0 && node.Name != "" {
+	if node.Name != "" {
 		count = 1
 	}
 
@@ -267,59 +495,47 @@ func pluralize(count int) string {
 }
 
 func runContextRename(cmd *cobra.Command, args []string) error {
-	oldContext := args[0]
-	newContext := args[1]
+	oldContext := models.NormalizeContext(args[0])
+	newContext := models.NormalizeContext(args[1])
 
 	if oldContext == newContext {
 		return fmt.Errorf("old and new context names are the same")
 	}
+	if oldContext != "" && newContext != "" && models.InContext(newContext, oldContext) {
+		return fmt.Errorf("cannot rename context %s to its own sub-context %s", oldContext, newContext)
+	}
 
 	store, err := storage.NewFileStore()
 	if err != nil {
 		return fmt.Errorf("failed to create store: %w", err)
 	}
 
-	// Count how many prompts will be affected
 	promptStore, err := store.LoadAll()
 	if err != nil {
 		return fmt.Errorf("failed to load prompts: %w", err)
 	}
 
-	affectedCount := 0
-	for _, p := range promptStore.Prompts {
-		// Exact match or prefix match with sub-contexts
-		if p.Context == oldContext || strings.HasPrefix(p.Context, oldContext+"/") {
-			affectedCount++
+	// Rename within one project unless --all is given
+	project := projectRef{Name: "every project"}
+	if !contextRenameAll {
+		project, err = contextScope(promptStore)
+		if err != nil {
+			return err
 		}
 	}
 
-	if affectedCount == 0 {
+	if !contextExists(promptStore, project.ID, oldContext) {
+		if project.ID != "" {
+			return fmt.Errorf("no prompts found with context '%s' in project %s (use --all to rename it in every project)", oldContext, project.Name)
+		}
 		return fmt.Errorf("no prompts found with context '%s'", oldContext)
 	}
-
-	// Perform the rename
-	err = store.BulkUpdate(func(p *models.Prompt) bool {
-		if p.Context == oldContext {
-			// Exact match - replace entirely
-			p.Context = newContext
-			return true
-		} else if strings.HasPrefix(p.Context, oldContext+"/") {
-			// Sub-context - replace prefix
-			remainder := strings.TrimPrefix(p.Context, oldContext+"/")
-			if newContext == "" {
-				p.Context = remainder
-			} else {
-				p.Context = newContext + "/" + remainder
-			}
-			return true
-		}
-		return false
-	})
-
-	if err != nil {
-		return fmt.Errorf("failed to rename context: %w", err)
+	if newContext != "" && contextExists(promptStore, project.ID, newContext) {
+		return fmt.Errorf("context %s already exists. Use 'pmt context merge %s %s' to combine them", newContext, oldContext, newContext)
 	}
 
+	affected := contextPrompts(promptStore.Prompts, project.ID, oldContext)
+
 	displayOld := oldContext
 	if displayOld == "" {
 		displayOld = "(no context)"
@@ -329,8 +545,23 @@ func runContextRename(cmd *cobra.Command, args []string) error {
 		displayNew = "(no context)"
 	}
 
+	if contextDryRun {
+		fmt.Printf("Would rename context %s → %s in %s (%d prompt%s):\n",
+			displayOld, displayNew, project.Name, len(affected), pluralize(len(affected)))
+		printAffected(affected)
+		return nil
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		moveContext(s, project.ID, oldContext, newContext)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename context: %w", err)
+	}
+
 	fmt.Printf("✓ Renamed context: %s → %s\n", displayOld, displayNew)
-	fmt.Printf("  Updated %d prompt%s\n", affectedCount, pluralize(affectedCount))
+	fmt.Printf("  Updated %d prompt%s\n", len(affected), pluralize(len(affected)))
 
 	return nil
 }
@@ -0,0 +1,719 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/ui"
+)
+
+var (
+	contextProject     string
+	contextDryRun      bool
+	contextDescription string
+	contextType        string
+	contextTags        []string
+	contextArchive     bool
+	contextUnarchive   bool
+	contextDeleteForce bool
+)
+
+var contextCreateCmd = &cobra.Command{
+	Use:   "create <context>",
+	Short: "Create a context with a description and defaults",
+	Long: `Create a context in the current project (or --project), before it has any
+prompts.
+
+A context can carry a description, a default type and default tags. Prompts
+pushed into the context get its type unless --type is given, and its tags in
+addition to their own.`,
+	Example: `  pmt context create backend/api -d "REST handlers"
+  pmt ctx create backend/auth -t bugfix --tags auth,security`,
+	Args: cobra.ExactArgs(1),
+	RunE: runContextCreate,
+}
+
+var contextDescribeCmd = &cobra.Command{
+	Use:   "describe <context>",
+	Short: "Show or change a context's description and defaults",
+	Long: `Show a context's description, defaults and prompt count, or change them
+with the flags below.
+
+An archived context and its sub-contexts no longer accept new prompts; their
+existing prompts are kept. Use --unarchive to open it again.`,
+	Example: `  pmt context describe backend/api
+  pmt ctx describe backend/api -d "REST handlers" -t feature
+  pmt ctx describe legacy --archive`,
+	Args: cobra.ExactArgs(1),
+	RunE: runContextDescribe,
+}
+
+var contextDeleteCmd = &cobra.Command{
+	Use:     "delete <context>",
+	Aliases: []string{"rm"},
+	Short:   "Delete a context and its sub-contexts",
+	Long: `Delete a context and its sub-contexts from the current project (or --project).
+
+A context that still has prompts is only deleted with --force, which moves
+the prompts to the trash. To keep them, merge the context into another one
+with 'pmt context merge' instead.`,
+	Example: `  pmt context delete scratch
+  pmt ctx rm old-feature --force --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runContextDelete,
+}
+
+var contextMergeCmd = &cobra.Command{
+	Use:   "merge <from> <into>",
+	Short: "Move all prompts of one context into another",
+	Long: `Move the prompts of a context and its sub-contexts into another context of
+the same project, which may already exist.
+
+Sub-contexts keep their place below the new context, and the description and
+defaults of the target context are kept; those it doesn't set are taken over.`,
+	Example: `  pmt context merge backend/rest backend/api
+  pmt ctx merge old-auth auth --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: runContextMerge,
+}
+
+var contextNormalizeCmd = &cobra.Command{
+	Use:   "normalize",
+	Short: "Rewrite stored contexts in their canonical form",
+	Long: `Rewrite the contexts of all prompts, trashed prompts and context records in
+their canonical form: lowercase, separated by single slashes, without leading
+or trailing slashes or whitespace. For example " Backend//API/ " becomes
+"backend/api".
+
+New prompts are always stored this way, and contexts are compared in this
+form everywhere, so "Backend" and "backend" are the same context. Run this
+once to clean up prompts saved by earlier versions; contexts that differ only
+in spelling are merged and reported.`,
+	Example: `  pmt context normalize --dry-run
+  pmt context normalize`,
+	Args: cobra.NoArgs,
+	RunE: runContextNormalize,
+}
+
+func init() {
+	contextCmd.AddCommand(contextCreateCmd)
+	contextCmd.AddCommand(contextDescribeCmd)
+	contextCmd.AddCommand(contextDeleteCmd)
+	contextCmd.AddCommand(contextMergeCmd)
+	contextCmd.AddCommand(contextNormalizeCmd)
+
+	for _, c := range []*cobra.Command{contextCreateCmd, contextDescribeCmd, contextDeleteCmd, contextMergeCmd, contextRenameCmd} {
+		c.Flags().StringVarP(&contextProject, "project", "p", "", "Project of the context (default: the current project)")
+	}
+	for _, c := range []*cobra.Command{contextDeleteCmd, contextMergeCmd, contextRenameCmd, contextNormalizeCmd} {
+		c.Flags().BoolVar(&contextDryRun, "dry-run", false, "Show what would change without changing anything")
+	}
+	for _, c := range []*cobra.Command{contextCreateCmd, contextDescribeCmd} {
+		c.Flags().StringVarP(&contextDescription, "description", "d", "", "Description of the context")
+		c.Flags().StringVarP(&contextType, "type", "t", "", "Default type of prompts pushed into the context")
+		c.Flags().StringSliceVarP(&contextTags, "tags", "g", []string{}, "Tags added to prompts pushed into the context (comma-separated)")
+	}
+	contextDescribeCmd.Flags().BoolVar(&contextArchive, "archive", false, "Stop accepting new prompts in the context")
+	contextDescribeCmd.Flags().BoolVar(&contextUnarchive, "unarchive", false, "Accept new prompts in the context again")
+	contextDeleteCmd.Flags().BoolVarP(&contextDeleteForce, "force", "f", false, "Move the context's prompts to the trash")
+}
+
+// contextScope returns the project a context command works on: --project, or
+// the project of the current directory
+func contextScope(promptStore *models.PromptStore) (projectRef, error) {
+	if contextProject == "" {
+		project := currentProject()
+		return projectRef{ID: project.ID, Name: project.Name}, nil
+	}
+
+	project, err := resolveProject(promptStore.Prompts, contextProject)
+	if err == nil {
+		return project, nil
+	}
+
+	// A project may only have context records so far
+	for _, c := range promptStore.Contexts {
+		if strings.EqualFold(c.Project, contextProject) {
+			return projectRef{ID: c.Project, Name: c.Project}, nil
+		}
+	}
+	return projectRef{}, err
+}
+
+// contextPrompts returns the prompts of a project in a context or its
+// sub-contexts; an empty project matches every project
+func contextPrompts(prompts []models.Prompt, project, context string) []*models.Prompt {
+	var matches []*models.Prompt
+	for i := range prompts {
+		p := &prompts[i]
+		if (project == "" || p.Project == project) && models.InContext(p.Context, context) {
+			matches = append(matches, p)
+		}
+	}
+	return matches
+}
+
+// contextRecords returns the indexes of the records of a project in a context
+// or its sub-contexts; an empty project matches every project
+func contextRecords(promptStore *models.PromptStore, project, context string) []int {
+	var matches []int
+	for i, c := range promptStore.Contexts {
+		if (project == "" || c.Project == project) && models.InContext(c.Path, context) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
+
+// contextExists reports whether a project has prompts or a record in a context
+func contextExists(promptStore *models.PromptStore, project, context string) bool {
+	return len(contextPrompts(promptStore.Prompts, project, context)) > 0 ||
+		len(contextRecords(promptStore, project, context)) > 0
+}
+
+// moveContext moves the prompts and records of a context and its
+// sub-contexts to another path; an empty project moves them in every project.
+// A record landing on an existing one is merged into it.
+func moveContext(promptStore *models.PromptStore, project, from, to string) {
+	for i := range promptStore.Prompts {
+		p := &promptStore.Prompts[i]
+		if project == "" || p.Project == project {
+			p.Context, _ = models.MoveContext(p.Context, from, to)
+		}
+	}
+
+	// Records that stay go first, so moved ones merge into them
+	var records, moved []models.Context
+	for _, c := range promptStore.Contexts {
+		path, ok := models.MoveContext(c.Path, from, to)
+		if !ok || (project != "" && c.Project != project) {
+			records = append(records, c)
+			continue
+		}
+		if path == "" {
+			// Prompts without a context have no record
+			continue
+		}
+		c.Path = path
+		moved = append(moved, c)
+	}
+	for _, c := range moved {
+		records = appendContext(records, c)
+	}
+	promptStore.Contexts = records
+}
+
+// appendContext adds a record, merging it into an existing record of the same
+// context: the existing one keeps its settings and takes over those it lacks
+func appendContext(records []models.Context, c models.Context) []models.Context {
+	for i := range records {
+		existing := &records[i]
+		if existing.Project != c.Project || existing.Path != c.Path {
+			continue
+		}
+		if existing.Description == "" {
+			existing.Description = c.Description
+		}
+		if existing.Type == "" {
+			existing.Type = c.Type
+		}
+		for _, tag := range c.Tags {
+			if !containsFold(existing.Tags, tag) {
+				existing.Tags = append(existing.Tags, tag)
+			}
+		}
+		return records
+	}
+	return append(records, c)
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkArchived fails when path is an archived context of the project, or
+// one of its sub-contexts
+func checkArchived(promptStore *models.PromptStore, project, path string) error {
+	if path == "" {
+		return nil
+	}
+	for _, c := range promptStore.Contexts {
+		if c.Archived && c.Project == project && models.InContext(path, c.Path) {
+			return fmt.Errorf("context %s is archived. Use 'pmt context describe %s --unarchive' to open it again", c.Path, c.Path)
+		}
+	}
+	return nil
+}
+
+// applyContextDefaults gives a new prompt the default type and tags of its
+// context; typeSet means the type was chosen explicitly and is kept.
+// Pushing into an archived context or one of its sub-contexts fails.
+func applyContextDefaults(store storage.Store, p *models.Prompt, typeSet bool) error {
+	if p.Context == "" {
+		return nil
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	if err := checkArchived(promptStore, p.Project, p.Context); err != nil {
+		return err
+	}
+
+	record := promptStore.FindContext(p.Project, p.Context)
+	if record == nil {
+		return nil
+	}
+
+	if record.Type != "" && !typeSet {
+		p.Type = record.Type
+	}
+	for _, tag := range record.Tags {
+		if !containsFold(p.Tags, tag) {
+			p.Tags = append(p.Tags, tag)
+		}
+	}
+	return nil
+}
+
+func runContextCreate(cmd *cobra.Command, args []string) error {
+	path := models.NormalizeContext(args[0])
+	if path == "" {
+		return fmt.Errorf("context cannot be empty")
+	}
+
+	policy, err := tagPolicy()
+	if err != nil {
+		return err
+	}
+	contextTags = policy.NormalizeTags(contextTags)
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+	if contextType != "" {
+		if contextType, err = validateType(promptStore, contextType); err != nil {
+			return err
+		}
+	}
+
+	project, err := contextScope(promptStore)
+	if err != nil {
+		return err
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		if s.FindContext(project.ID, path) != nil {
+			return fmt.Errorf("context %s already exists in project %s. Use 'pmt context describe' to change it", path, project.Name)
+		}
+		s.Contexts = append(s.Contexts, models.Context{
+			Path:        path,
+			Project:     project.ID,
+			Description: strings.TrimSpace(contextDescription),
+			Type:        contextType,
+			Tags:        contextTags,
+			CreatedAt:   time.Now(),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Created context: %s in project: %s\n", path, project.Name)
+	return nil
+}
+
+func runContextDescribe(cmd *cobra.Command, args []string) error {
+	path := models.NormalizeContext(args[0])
+	if path == "" {
+		return fmt.Errorf("context cannot be empty")
+	}
+	if contextArchive && contextUnarchive {
+		return fmt.Errorf("--archive and --unarchive cannot be used together")
+	}
+
+	policy, err := tagPolicy()
+	if err != nil {
+		return err
+	}
+	contextTags = policy.NormalizeTags(contextTags)
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+	if contextType != "" {
+		if contextType, err = validateType(promptStore, contextType); err != nil {
+			return err
+		}
+	}
+
+	project, err := contextScope(promptStore)
+	if err != nil {
+		return err
+	}
+	if !contextExists(promptStore, project.ID, path) {
+		return fmt.Errorf("context %s not found in project %s. Use 'pmt context create' to add it", path, project.Name)
+	}
+
+	flags := cmd.Flags()
+	changed := flags.Changed("description") || flags.Changed("type") || flags.Changed("tags") ||
+		contextArchive || contextUnarchive
+	if !changed {
+		printContextDetails(promptStore, project, path)
+		return nil
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		record := s.FindContext(project.ID, path)
+		if record == nil {
+			// The context so far only exists on its prompts
+			s.Contexts = append(s.Contexts, models.Context{
+				Path:      path,
+				Project:   project.ID,
+				CreatedAt: time.Now(),
+			})
+			record = &s.Contexts[len(s.Contexts)-1]
+		}
+
+		if flags.Changed("description") {
+			record.Description = strings.TrimSpace(contextDescription)
+		}
+		if flags.Changed("type") {
+			record.Type = contextType
+		}
+		if flags.Changed("tags") {
+			record.Tags = contextTags
+		}
+		if contextArchive {
+			record.Archived = true
+		}
+		if contextUnarchive {
+			record.Archived = false
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update context: %w", err)
+	}
+
+	fmt.Printf("✓ Updated context: %s\n", path)
+	return nil
+}
+
+// printContextDetails prints a context's record and prompt counts
+func printContextDetails(promptStore *models.PromptStore, project projectRef, path string) {
+	record := promptStore.FindContext(project.ID, path)
+	if record == nil {
+		record = &models.Context{Path: path, Project: project.ID}
+	}
+
+	direct := 0
+	prompts := contextPrompts(promptStore.Prompts, project.ID, path)
+	for _, p := range prompts {
+		if p.Context == path {
+			direct++
+		}
+	}
+
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Printf("Context:      %s\n", path)
+	fmt.Printf("Project:      %s\n", project)
+
+	if record.Description != "" {
+		fmt.Printf("Description:  %s\n", record.Description)
+	}
+
+	if record.Type != "" {
+		fmt.Printf("Default type: %s\n", record.Type)
+	}
+
+	if len(record.Tags) > 0 {
+		fmt.Printf("Default tags: %s\n", strings.Join(record.Tags, ", "))
+	}
+
+	if record.Archived {
+		fmt.Println("Status:       archived")
+	}
+
+	fmt.Printf("Prompts:      %d (%d including sub-contexts)\n", direct, len(prompts))
+
+	if !record.CreatedAt.IsZero() {
+		fmt.Printf("Created:      %s\n", ui.FormatDate(record.CreatedAt))
+	}
+	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+}
+
+func runContextDelete(cmd *cobra.Command, args []string) error {
+	path := models.NormalizeContext(args[0])
+	if path == "" {
+		return fmt.Errorf("context cannot be empty")
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	project, err := contextScope(promptStore)
+	if err != nil {
+		return err
+	}
+	if !contextExists(promptStore, project.ID, path) {
+		return fmt.Errorf("context %s not found in project %s", path, project.Name)
+	}
+
+	affected := contextPrompts(promptStore.Prompts, project.ID, path)
+	if len(affected) > 0 && !contextDeleteForce && !contextDryRun {
+		return fmt.Errorf("context %s has %d prompt%s. Use --force to move them to the trash, or 'pmt context merge %s <context>' to keep them",
+			path, len(affected), pluralize(len(affected)), path)
+	}
+
+	if contextDryRun {
+		fmt.Printf("Would delete context %s from project %s", path, project.Name)
+		if len(affected) > 0 {
+			fmt.Printf(" and move %d prompt%s to the trash:\n", len(affected), pluralize(len(affected)))
+			printAffected(affected)
+		} else {
+			fmt.Println()
+		}
+		return nil
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		trashed := make(map[int]bool)
+		for i := range s.Prompts {
+			if s.Prompts[i].Project == project.ID && models.InContext(s.Prompts[i].Context, path) {
+				trashed[i] = true
+			}
+		}
+		storage.MoveToTrash(s, trashed)
+
+		var records []models.Context
+		for _, c := range s.Contexts {
+			if c.Project != project.ID || !models.InContext(c.Path, path) {
+				records = append(records, c)
+			}
+		}
+		s.Contexts = records
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete context: %w", err)
+	}
+
+	fmt.Printf("✓ Deleted context: %s\n", path)
+	if len(affected) > 0 {
+		fmt.Printf("  Moved %d prompt%s to the trash; restore them with 'pmt trash restore'\n", len(affected), pluralize(len(affected)))
+	}
+	return nil
+}
+
+func runContextMerge(cmd *cobra.Command, args []string) error {
+	from := models.NormalizeContext(args[0])
+	into := models.NormalizeContext(args[1])
+	if from == "" || into == "" {
+		return fmt.Errorf("context cannot be empty")
+	}
+	if from == into {
+		return fmt.Errorf("cannot merge context %s into itself", from)
+	}
+	if models.InContext(into, from) {
+		return fmt.Errorf("cannot merge context %s into its own sub-context %s", from, into)
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	project, err := contextScope(promptStore)
+	if err != nil {
+		return err
+	}
+	if !contextExists(promptStore, project.ID, from) {
+		return fmt.Errorf("context %s not found in project %s", from, project.Name)
+	}
+
+	affected := contextPrompts(promptStore.Prompts, project.ID, from)
+	if contextDryRun {
+		fmt.Printf("Would merge context %s into %s (%d prompt%s):\n", from, into, len(affected), pluralize(len(affected)))
+		printAffected(affected)
+		return nil
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		moveContext(s, project.ID, from, into)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to merge contexts: %w", err)
+	}
+
+	fmt.Printf("✓ Merged context %s into %s\n", from, into)
+	fmt.Printf("  Moved %d prompt%s\n", len(affected), pluralize(len(affected)))
+	return nil
+}
+
+// contextSpelling is a context of a project in canonical form, and the
+// spellings of it found in the store
+type contextSpelling struct {
+	project   string
+	path      string
+	spellings []string
+	prompts   int
+}
+
+// contextSpellings groups the stored contexts of prompts and records by
+// project and canonical form, keeping only those that are not canonical yet
+func contextSpellings(promptStore *models.PromptStore) []*contextSpelling {
+	var order []*contextSpelling
+	groups := make(map[[2]string]*contextSpelling)
+	add := func(project, path string, prompt bool) {
+		key := [2]string{project, models.NormalizeContext(path)}
+		g := groups[key]
+		if g == nil {
+			g = &contextSpelling{project: project, path: key[1]}
+			groups[key] = g
+			order = append(order, g)
+		}
+		if prompt {
+			g.prompts++
+		}
+		for _, s := range g.spellings {
+			if s == path {
+				return
+			}
+		}
+		g.spellings = append(g.spellings, path)
+	}
+
+	for _, p := range promptStore.Prompts {
+		add(p.Project, p.Context, true)
+	}
+	for _, p := range promptStore.Trash {
+		add(p.Project, p.Context, true)
+	}
+	for _, c := range promptStore.Contexts {
+		add(c.Project, c.Path, false)
+	}
+
+	var changed []*contextSpelling
+	for _, g := range order {
+		if len(g.spellings) > 1 || g.spellings[0] != g.path {
+			changed = append(changed, g)
+		}
+	}
+	return changed
+}
+
+func runContextNormalize(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	changed := contextSpellings(promptStore)
+	if len(changed) == 0 {
+		fmt.Println("All contexts are already in canonical form.")
+		return nil
+	}
+
+	names := make(map[string]string)
+	for i := range promptStore.Prompts {
+		names[promptStore.Prompts[i].Project] = promptStore.Prompts[i].DisplayProject()
+	}
+
+	if contextDryRun {
+		fmt.Println("Would normalize these contexts:")
+	}
+	merged := 0
+	for _, g := range changed {
+		name := names[g.project]
+		if name == "" {
+			name = g.project
+		}
+		path := g.path
+		if path == "" {
+			path = "(no context)"
+		}
+		spellings := make([]string, len(g.spellings))
+		for i, s := range g.spellings {
+			spellings[i] = "'" + s + "'"
+		}
+		note := ""
+		if len(g.spellings) > 1 {
+			note = " (merged)"
+			merged++
+		}
+		fmt.Printf("  %s: %s → %s, %d prompt%s%s\n", name, strings.Join(spellings, ", "), path, g.prompts, pluralize(g.prompts), note)
+	}
+	if contextDryRun {
+		return nil
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		for i := range s.Prompts {
+			s.Prompts[i].Context = models.NormalizeContext(s.Prompts[i].Context)
+		}
+		for i := range s.Trash {
+			s.Trash[i].Context = models.NormalizeContext(s.Trash[i].Context)
+		}
+
+		// Records that end up on the same context are merged
+		var records []models.Context
+		for _, c := range s.Contexts {
+			c.Path = models.NormalizeContext(c.Path)
+			if c.Path != "" {
+				records = appendContext(records, c)
+			}
+		}
+		s.Contexts = records
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to normalize contexts: %w", err)
+	}
+
+	fmt.Printf("✓ Normalized %d context%s, merging %d\n", len(changed), pluralize(len(changed)), merged)
+	return nil
+}
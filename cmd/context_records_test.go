@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+func TestCheckArchived(t *testing.T) {
+	s := &models.PromptStore{Contexts: []models.Context{
+		{Path: "legacy", Project: "api", Archived: true},
+		{Path: "backend", Project: "api"},
+		{Path: "old", Project: "web", Archived: true},
+	}}
+
+	tests := []struct {
+		project string
+		path    string
+		wantErr bool
+	}{
+		{"api", "legacy", true},
+		{"api", "Legacy/Sub", true},
+		{"api", "legacy-v2", false},
+		{"api", "backend/api", false},
+		{"api", "old", false},
+		{"web", "old/x", true},
+		{"api", "", false},
+	}
+	for _, tt := range tests {
+		err := checkArchived(s, tt.project, tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkArchived(%s, %q) = %v, want error %v", tt.project, tt.path, err, tt.wantErr)
+		}
+	}
+}
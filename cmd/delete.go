@@ -1,16 +1,17 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/ui"
 )
 
-var deleteForce bool
+var (
+	deleteForce bool
+	deleteMulti bool
+)
 
 var deleteCmd = &cobra.Command{
 	Use:   "delete <id>",
@@ -18,21 +19,36 @@ var deleteCmd = &cobra.Command{
 	Long: `Delete a specific prompt by its ID.
 
 You can use the full ID or just a prefix (e.g., 'a7f' instead of 'a7f3c2b').
-By default, you will be asked to confirm the deletion.`,
+By default, you will be asked to confirm the deletion.
+
+With --multi, pass several IDs or none to mark prompts in the selector.
+They are deleted together in a single transaction.`,
 	Example: `  pmt delete a7f3c2b
   pmt delete a7f
-  pmt delete a7f -f  # Force delete without confirmation`,
+  pmt delete a7f -f  # Force delete without confirmation
+  pmt delete -m      # Mark several prompts to delete
+  pmt delete -m a7f 9d4`,
 	Aliases: []string{"rm"},
-	Args:    cobra.ExactArgs(1),
-	RunE:    runDelete,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if deleteMulti {
+			return nil
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: runDelete,
 }
 
 func init() {
 	rootCmd.AddCommand(deleteCmd)
 	deleteCmd.Flags().BoolVarP(&deleteForce, "force", "f", false, "Force deletion without confirmation")
+	deleteCmd.Flags().BoolVarP(&deleteMulti, "multi", "m", false, "Delete several prompts at once")
 }
 
 func runDelete(cmd *cobra.Command, args []string) error {
+	if deleteMulti {
+		return runDeleteMulti(args)
+	}
+
 	id := args[0]
 
 	store, err := storage.NewFileStore()
@@ -48,17 +64,11 @@ func runDelete(cmd *cobra.Command, args []string) error {
 
 	// Ask for confirmation unless force flag is set
 	if !deleteForce {
-		fmt.Printf("Delete prompt %s? (%s)\n", prompt.ID, truncateString(prompt.Content, 50))
-		fmt.Print("Type 'yes' to confirm: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+		confirmed, err := ui.Confirm(fmt.Sprintf("Delete prompt %s? (%s)", prompt.ID, truncateString(prompt.Text(), 50)))
 		if err != nil {
-			return fmt.Errorf("failed to read confirmation: %w", err)
+			return err
 		}
-
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "yes" && response != "y" {
+		if !confirmed {
 			fmt.Println("Deletion cancelled.")
 			return nil
 		}
@@ -72,3 +82,54 @@ func runDelete(cmd *cobra.Command, args []string) error {
 	fmt.Printf("✓ Deleted prompt: %s\n", prompt.ID)
 	return nil
 }
+
+// runDeleteMulti deletes several prompts, given as IDs or marked in the selector
+func runDeleteMulti(args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	if len(promptStore.Prompts) == 0 {
+		return fmt.Errorf("no prompts available")
+	}
+	if len(args) == 0 {
+		setTypeColors(store)
+	}
+
+	selected, _, err := choosePrompts(promptStore.Prompts, args, ui.PickerOptions{Multi: true})
+	if err != nil {
+		return err
+	}
+
+	if !deleteForce {
+		fmt.Printf("Prompts to delete (%d):\n", len(selected))
+		for _, p := range selected {
+			fmt.Printf("  %s  %s\n", p.ID, truncateString(p.Text(), 50))
+		}
+		confirmed, err := ui.Confirm(fmt.Sprintf("Delete %d prompt%s?", len(selected), pluralize(len(selected))))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Deletion cancelled.")
+			return nil
+		}
+	}
+
+	ids := make([]string, len(selected))
+	for i, p := range selected {
+		ids[i] = p.ID
+	}
+	if err := store.DeleteMany(ids); err != nil {
+		return fmt.Errorf("failed to delete prompts: %w", err)
+	}
+
+	fmt.Printf("✓ Deleted %d prompt%s: %s\n", len(ids), pluralize(len(ids)), joinIDs(selected))
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package cmd
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts the process without a console so it outlives the terminal
+func detach(c *exec.Cmd) {
+	const detachedProcess = 0x00000008
+	c.SysProcAttr = &syscall.SysProcAttr{CreationFlags: detachedProcess}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+)
+
+var (
+	globalUnset bool
+)
+
+var globalCmd = &cobra.Command{
+	Use:   "global <id>",
+	Short: "Show a prompt in every project",
+	Long: `Mark a prompt as global, so apply, pop and list offer it in every project
+and not only in the one it was saved in.
+
+Use --unset to limit it to its own project again.`,
+	Example: `  pmt global a7f
+  pmt global a7f --unset`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGlobal,
+}
+
+func init() {
+	rootCmd.AddCommand(globalCmd)
+	globalCmd.Flags().BoolVar(&globalUnset, "unset", false, "Limit the prompt to its own project again")
+}
+
+func runGlobal(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	prompt, err := findPrompt(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	err = store.Update(prompt.ID, func(p *models.Prompt) {
+		p.Global = !globalUnset
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update prompt: %w", err)
+	}
+
+	if globalUnset {
+		fmt.Printf("✓ Prompt %s is now limited to project: %s\n", prompt.ID, prompt.DisplayProject())
+	} else {
+		fmt.Printf("✓ Prompt %s is now shown in every project\n", prompt.ID)
+	}
+	return nil
+}
@@ -3,9 +3,13 @@ package cmd
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/config"
+	"github.com/sunny/pmt/internal/models"
 	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/ui"
 )
 
 var (
@@ -13,6 +17,11 @@ var (
 	listProject       string
 	listContext       string
 	listContextPrefix bool
+	listAll           bool
+	listBranch        string
+	listArchived      bool
+	listColumnNames   string
+	listTags          []string
 )
 
 var listCmd = &cobra.Command{
@@ -20,14 +29,29 @@ var listCmd = &cobra.Command{
 	Short: "List all prompts",
 	Long: `List all saved prompts in a table format.
 
-You can filter by type, project, or context using flags.
-Use --prefix to match context hierarchically (e.g., "backend" matches "backend/api").`,
+Only prompts from the current git project, plus global prompts, are listed
+unless --all or --project is given. Set 'scope: all' in ~/.pmt/config.yaml
+to list every project by default.
+
+You can filter by type, project, context or tags using flags.
+Use --prefix to match context hierarchically (e.g., "backend" matches "backend/api").
+Use --branch to list only the prompts pushed on a branch, and --archived to
+list the prompts archived by 'pmt prune'.
+
+The columns shown are set by the list_columns setting (see 'pmt config'), or
+for a single listing by --columns: id, name, type, project, context, branch,
+tags, content and created. Multi-line content is shown on one line.`,
 	Example: `  pmt list
+  pmt list --all
   pmt list -t bugfix
   pmt list -p my-api
   pmt list -c backend --prefix       # Match backend and all sub-contexts
   pmt list -c backend/api            # Exact match only
-  pmt list -t feature -p my-api`,
+  pmt list -t feature -p my-api
+  pmt list --tag go,security         # Prompts with both tags
+  pmt list --branch feature/login
+  pmt list --archived
+  pmt list --columns id,name,tags`,
 	Aliases: []string{"ls"},
 	RunE:    runList,
 }
@@ -37,21 +61,54 @@ func init() {
 	listCmd.Flags().StringVarP(&listType, "type", "t", "", "Filter by type")
 	listCmd.Flags().StringVarP(&listProject, "project", "p", "", "Filter by project")
 	listCmd.Flags().StringVarP(&listContext, "context", "c", "", "Filter by context")
+	listCmd.Flags().StringSliceVarP(&listTags, "tag", "g", nil, "Filter by tags (comma-separated; prompts must have all of them)")
+	listCmd.Flags().BoolVarP(&listAll, "all", "a", false, "List prompts from every project")
+	listCmd.Flags().StringVarP(&listBranch, "branch", "b", "", "Filter by the branch prompts were pushed on")
+	listCmd.Flags().BoolVar(&listArchived, "archived", false, "List archived prompts instead")
+	listCmd.Flags().StringVar(&listColumnNames, "columns", "", "Columns to show, comma-separated (overrides the list_columns setting)")
 	listCmd.Flags().BoolVar(&listContextPrefix, "prefix", false, "Match context as prefix (e.g., 'backend' matches 'backend/api')")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Changed("columns") {
+		if err := config.Override("list_columns", listColumnNames); err != nil {
+			return err
+		}
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
 	store, err := storage.NewFileStore()
 	if err != nil {
 		return fmt.Errorf("failed to create store: %w", err)
 	}
+	if err := checkType(store, listType); err != nil {
+		return err
+	}
 
-	// Apply filters
+	// Apply filters; without --project, default to the current project
 	filterOpts := storage.FilterOptions{
 		Type:          listType,
 		Project:       listProject,
 		Context:       listContext,
 		ContextPrefix: listContextPrefix,
+		Tags:          listTags,
+		Branch:        listBranch,
+		Archived:      listArchived,
+	}
+	if listProject == "" {
+		filterOpts.Project, err = currentScope(listAll)
+		if err != nil {
+			return err
+		}
+		filterOpts.IncludeGlobal = true
+	} else {
+		filterOpts.Project, err = projectFilter(store, listProject)
+		if err != nil {
+			return err
+		}
 	}
 
 	prompts, err := store.Filter(filterOpts)
@@ -59,51 +116,115 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load prompts: %w", err)
 	}
 
+	scoped := listProject == "" && filterOpts.Project != ""
+	hint := ""
+	if scoped {
+		hint = legacyHint(store)
+	}
+
 	if len(prompts) == 0 {
+		if scoped {
+			fmt.Printf("No prompts found in project %s. Use --all to list every project.\n", scopeName(filterOpts.Project))
+			if hint != "" {
+				fmt.Println(hint)
+			}
+			return nil
+		}
 		fmt.Println("No prompts found.")
 		return nil
 	}
 
-	// Print header
-	fmt.Printf("%-9s %-20s %-10s %-12s %-12s %-30s %s\n", "ID", "Name", "Type", "Project", "Context", "Content", "Created")
-	fmt.Println(strings.Repeat("-", 125))
+	// Print the configured columns
+	columns := make([]listColumn, 0, len(cfg.Columns()))
+	for _, name := range cfg.Columns() {
+		columns = append(columns, listColumns[name])
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.header
+	}
+	printRow(columns, header)
+	fmt.Println(strings.Repeat("-", tableWidth(columns)))
 
-	// Print each prompt
-	for _, p := range prompts {
-		content := p.Content
-		if len(content) > 30 {
-			content = content[:27] + "..."
+	for i := range prompts {
+		row := make([]string, len(columns))
+		for j, column := range columns {
+			row[j] = column.value(&prompts[i])
 		}
+		printRow(columns, row)
+	}
+
+	fmt.Printf("\nTotal: %d prompt(s)\n", len(prompts))
+	if hint != "" {
+		fmt.Println(hint)
+	}
+	if path := store.RepoPath(); path != "" {
+		fmt.Printf("💡 Including the repository's store %s (prompt_dir in %s); new prompts of this repository are saved there\n", path, config.RepoFile)
+	}
+	return nil
+}
+
+// listColumn is a column of 'pmt list'
+type listColumn struct {
+	header string
+	width  int
+	value  func(p *models.Prompt) string
+}
 
-		name := p.Name
-		if name == "" {
-			name = "-"
+// listColumns are the columns 'pmt list' can show, by config name
+var listColumns = map[string]listColumn{
+	"id":   {"ID", 9, func(p *models.Prompt) string { return p.ID }},
+	"name": {"Name", 20, func(p *models.Prompt) string { return orDash(p.Name) }},
+	"type": {"Type", 10, func(p *models.Prompt) string { return p.Type }},
+	"project": {"Project", 12, func(p *models.Prompt) string {
+		if p.Global {
+			return "(global)"
 		}
+		return p.DisplayProject()
+	}},
+	"context": {"Context", 12, func(p *models.Prompt) string { return orDash(p.Context) }},
+	"branch":  {"Branch", 16, func(p *models.Prompt) string { return orDash(p.Branch) }},
+	"tags":    {"Tags", 16, func(p *models.Prompt) string { return orDash(strings.Join(p.Tags, ",")) }},
+	"content": {"Content", 30, func(p *models.Prompt) string { return strings.Join(strings.Fields(p.Text()), " ") }},
+	"created": {"Created", 16, func(p *models.Prompt) string { return ui.FormatDate(p.CreatedAt) }},
+}
 
-		context := p.Context
-		if context == "" {
-			context = "-"
+// printRow prints a table row, truncating each value to its column's width.
+// The last column is printed in full.
+func printRow(columns []listColumn, values []string) {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		if i == len(values)-1 {
+			cells[i] = value
+			break
 		}
+		value = truncateString(value, columns[i].width)
+		cells[i] = value + strings.Repeat(" ", columns[i].width-utf8.RuneCountInString(value))
+	}
+	fmt.Println(strings.Join(cells, " "))
+}
 
-		createdStr := p.CreatedAt.Format("2006-01-02 15:04")
-		fmt.Printf("%-9s %-20s %-10s %-12s %-12s %-30s %s\n",
-			p.ID,
-			truncateString(name, 20),
-			p.Type,
-			truncateString(p.Project, 12),
-			truncateString(context, 12),
-			content,
-			createdStr,
-		)
+// tableWidth returns the width of the separator line under the header
+func tableWidth(columns []listColumn) int {
+	width := 0
+	for _, column := range columns {
+		width += column.width + 1
 	}
+	return max(width-1, 0)
+}
 
-	fmt.Printf("\nTotal: %d prompt(s)\n", len(prompts))
-	return nil
+// orDash returns s, or "-" for an empty value
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
 
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if utf8.RuneCountInString(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	return string([]rune(s)[:maxLen-3]) + "..."
 }
@@ -51,12 +51,21 @@ func runMv(cmd *cobra.Command, args []string) error {
 		oldContext = "(no context)"
 	}
 
-	newContext := mvContext
+	newContext := models.NormalizeContext(mvContext)
 	displayNewContext := newContext
 	if displayNewContext == "" {
 		displayNewContext = "(no context)"
 	}
 
+	// Archived contexts accept no new prompts
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+	if err := checkArchived(promptStore, prompt.Project, newContext); err != nil {
+		return err
+	}
+
 	// Update the prompt's context
 	err = store.Update(id, func(p *models.Prompt) {
 		p.Context = newContext
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/storage"
+)
+
+var (
+	peekContext   string
+	peekAll       bool
+	peekBranch    string
+	peekAnyBranch bool
+	peekRaw       bool
+)
+
+var peekCmd = &cobra.Command{
+	Use:   "peek [id|name|index]",
+	Short: "Show the top prompt without consuming it",
+	Long: `Show the most recent prompt in the current project without copying or
+removing it, like looking at stash@{0}.
+
+Pass an index, name, or ID to look at a different entry. Use --raw to print
+only the content, for use in scripts.
+
+Peek offers the same prompts as pop: the current git project and branch, and
+global prompts, unless --all or --any-branch is given. So 'pmt peek' shows
+exactly what 'pmt pop 0' would take.`,
+	Example: `  pmt peek
+  pmt peek 2
+  pmt peek --raw | wc -c
+  pmt peek -c backend
+  pmt peek --all
+  pmt peek -b feature/login`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPeek,
+}
+
+func init() {
+	rootCmd.AddCommand(peekCmd)
+	peekCmd.Flags().StringVarP(&peekContext, "context", "c", "", "Filter by context")
+	peekCmd.Flags().BoolVarP(&peekAll, "all", "a", false, "Include prompts from every project, not only the current one")
+	peekCmd.Flags().StringVarP(&peekBranch, "branch", "b", "", "Offer prompts pushed on this branch (default: the current branch)")
+	peekCmd.Flags().BoolVar(&peekAnyBranch, "any-branch", false, "Offer prompts from every branch")
+	peekCmd.Flags().BoolVarP(&peekRaw, "raw", "r", false, "Print only the prompt content")
+}
+
+func runPeek(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	project, err := currentScope(peekAll)
+	if err != nil {
+		return err
+	}
+
+	// Same scope as pop, so peek is a dry run of it
+	branch := currentBranch(project, peekBranch, peekAnyBranch)
+	prompts, err := store.Filter(storage.FilterOptions{
+		Project:           project,
+		Context:           peekContext,
+		IncludeGlobal:     true,
+		Branch:            branch,
+		IncludeUnbranched: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	if len(prompts) == 0 {
+		return noPromptsError(project, branch)
+	}
+
+	ref := "0"
+	if len(args) > 0 {
+		ref = args[0]
+	}
+
+	prompt, err := resolvePrompt(prompts, ref)
+	if err != nil {
+		return scopeHint(err, project, branch, args)
+	}
+
+	if peekRaw {
+		fmt.Println(prompt.Text())
+		return nil
+	}
+
+	printPromptDetails(prompt, prompt.Text())
+	return nil
+}
@@ -2,43 +2,100 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/atotto/clipboard"
 	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/render"
 	"github.com/sunny/pmt/internal/storage"
 	"github.com/sunny/pmt/internal/ui"
 )
 
 var (
-	popContext string
+	popContext    string
+	popAll        bool
+	popBranch     string
+	popAnyBranch  bool
+	popMaxBytes   int
+	popVariant    string
+	popVars       map[string]string
+	popTo         string
+	popClearAfter time.Duration
+	popMulti      bool
+	popSeparator  string
 )
 
 var popCmd = &cobra.Command{
-	Use:   "pop",
+	Use:   "pop [id|name|index]",
 	Short: "Select, copy, and delete a prompt",
-	Long: `Interactively select a prompt from your saved prompts.
+	Long: `Interactively select a prompt from your saved prompts, or name it directly
+with a full or prefix ID, a prompt name, or a git-stash-style index
+(0 is the most recent prompt in the current project).
+
+Like apply, only prompts from the current git project and branch, and global
+prompts, are offered unless --all or --any-branch is given.
 
 The selected prompt will be copied to your clipboard and then deleted from storage.
-Similar to 'git stash pop' - use this when you want to consume the prompt.`,
+Similar to 'git stash pop' - use this when you want to consume the prompt.
+The prompt is only removed once it has been copied. See 'pmt apply --help'
+for how the clipboard backend is chosen.
+
+With --multi, several prompts are copied joined with --separator and then
+removed together in a single transaction.`,
 	Example: `  pmt pop
-  pmt pop -c backend`,
+  pmt pop 0
+  pmt pop a7f
+  pmt pop -c backend
+  pmt pop --all
+  pmt pop -b feature/login
+  pmt pop -m
+  pmt pop --to tmux`,
 	RunE: runPop,
 }
 
 func init() {
 	rootCmd.AddCommand(popCmd)
 	popCmd.Flags().StringVarP(&popContext, "context", "c", "", "Filter by context")
+	popCmd.Flags().BoolVarP(&popAll, "all", "a", false, "Include prompts from every project, not only the current one")
+	popCmd.Flags().StringVarP(&popBranch, "branch", "b", "", "Offer prompts pushed on this branch (default: the current branch)")
+	popCmd.Flags().BoolVar(&popAnyBranch, "any-branch", false, "Offer prompts from every branch")
+	popCmd.Flags().IntVar(&popMaxBytes, "max-bytes", render.DefaultMaxBytes, "Total byte budget for {{file:...}} includes")
+	popCmd.Flags().StringVar(&popVariant, "variant", "", "Variant of the prompt to copy")
+	popCmd.Flags().StringToStringVar(&popVars, "var", nil, "Set a template variable (name=value)")
+	popCmd.Flags().StringVar(&popTo, "to", "", "Clipboard backend: auto, system, xclip, xsel, wl-copy, pbcopy, osc52, tmux, stdout, file:<path>")
+	popCmd.Flags().BoolVarP(&popMulti, "multi", "m", false, "Select several prompts, copy them joined together and remove them all")
+	popCmd.Flags().StringVar(&popSeparator, "separator", `\n\n`, "Separator between prompts copied with --multi")
+	popCmd.Flags().DurationVar(&popClearAfter, "clear-after", 0, "Restore the previous clipboard contents after this long (e.g. 45s)")
 }
 
 func runPop(cmd *cobra.Command, args []string) error {
+	backend, err := clipboardBackend(popTo, popClearAfter > 0)
+	if err != nil {
+		return err
+	}
+
 	store, err := storage.NewFileStore()
 	if err != nil {
 		return fmt.Errorf("failed to create store: %w", err)
 	}
 
-	// Apply filters
+	project, err := currentScope(popAll)
+	if err != nil {
+		return err
+	}
+
+	// Apply filters; global prompts show up in every project, and prompts
+	// not tied to a branch on every branch
+	branch := currentBranch(project, popBranch, popAnyBranch)
 	filterOpts := storage.FilterOptions{
-		Context: popContext,
+		Project:           project,
+		Context:           popContext,
+		IncludeGlobal:     true,
+		Branch:            branch,
+		IncludeUnbranched: true,
+	}
+
+	if len(args) == 0 {
+		setTypeColors(store)
 	}
 
 	prompts, err := store.Filter(filterOpts)
@@ -47,27 +104,62 @@ func runPop(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(prompts) == 0 {
-		return fmt.Errorf("no prompts available. Use 'pmt push' to add prompts")
+		return noPromptsError(project, branch)
 	}
 
-	// Show interactive selector
-	selected, err := ui.SelectPrompt(prompts)
+	// Pick by reference, or show the interactive selector
+	selected, interactive, err := choosePrompts(prompts, args, ui.PickerOptions{Multi: popMulti})
 	if err != nil {
-		return fmt.Errorf("selection cancelled or failed: %w", err)
+		return scopeHint(err, project, branch, args)
+	}
+
+	content, err := buildOutput(store, selected, interactive, outputOptions{
+		Render: renderOptions{
+			MaxBytes: popMaxBytes,
+			Variant:  popVariant,
+			Vars:     popVars,
+		},
+		Format:    render.FormatPlain,
+		Separator: popSeparator,
+	})
+	if err != nil {
+		return err
+	}
+
+	var previous string
+	if popClearAfter > 0 {
+		previous, err = readPrevious(backend)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Copy to clipboard
-	if err := clipboard.WriteAll(selected.Content); err != nil {
-		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	if err := copyText(backend, content); err != nil {
+		return err
 	}
 
-	// Delete the prompt
-	if err := store.Delete(selected.ID); err != nil {
+	// Delete the prompts in one write, so either all or none are consumed
+	ids := make([]string, len(selected))
+	for i, p := range selected {
+		ids[i] = p.ID
+	}
+	if err := store.DeleteMany(ids); err != nil {
 		return fmt.Errorf("failed to delete prompt: %w", err)
 	}
 
-	fmt.Printf("\n✓ Copied and removed: %s\n", selected.ID)
-	fmt.Println("💡 Now paste (Ctrl+V) into Copilot!")
+	if popClearAfter > 0 {
+		if err := scheduleRestore(backend, previous, content, popClearAfter); err != nil {
+			return err
+		}
+	}
+
+	out := statusWriter(backend)
+	fmt.Fprintf(out, "\n✓ Copied (%s) and removed: %s\n", backend.Name(), joinIDs(selected))
+	fmt.Fprintln(out, "💡 Now paste (Ctrl+V) into Copilot!")
+	if popClearAfter > 0 {
+		fmt.Fprintf(out, "🔒 Clipboard will be restored in %s\n", popClearAfter)
+	}
 
 	return nil
 }
@@ -0,0 +1,634 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/utils"
+)
+
+var (
+	projectRelinkFrom string
+	projectDryRun     bool
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Manage projects",
+	Long: `Manage the projects prompts belong to.
+
+A project is identified by its normalized origin remote URL (for example
+github.com/org/api), or by its root commit hash when it has no remote, so
+clones in differently named folders share their prompts. The folder name is
+kept as the project's display name.
+
+Projects can be referred to by display name or ID, and "." is the project of
+the current directory.`,
+	Example: `  pmt project list
+  pmt project rename api billing-api
+  pmt project merge old-api .
+  pmt project delete scratch --dry-run
+  pmt project relink`,
+}
+
+var projectListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List projects with their prompt counts",
+	Long:    `List all projects that have prompts, marking the current one with *.`,
+	Example: `  pmt project list
+  pmt project ls`,
+	Args: cobra.NoArgs,
+	RunE: runProjectList,
+}
+
+var projectRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a project",
+	Long: `Change the display name of a project on all of its prompts.
+
+Prompts saved by earlier versions are keyed by the folder name itself; for
+those the key is renamed. To move prompts to another project, for example
+after a repository was renamed upstream, use 'pmt project merge'.`,
+	Example: `  pmt project rename api billing-api
+  pmt project rename api billing-api --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: runProjectRename,
+}
+
+var projectMergeCmd = &cobra.Command{
+	Use:   "merge <from> <into>",
+	Short: "Move all prompts of one project into another",
+	Long: `Move every prompt of the first project into the second one, which keeps
+its ID and display name. The first project no longer exists afterwards.
+
+Use "." for the project of the current directory, e.g. to carry prompts along
+after a repository was renamed or moved to another organization.`,
+	Example: `  pmt project merge old-api .
+  pmt project merge github.com/org/api github.com/org/api-v2 --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: runProjectMerge,
+}
+
+var projectDeleteCmd = &cobra.Command{
+	Use:     "delete <name>",
+	Aliases: []string{"rm"},
+	Short:   "Move all prompts of a project to the trash",
+	Long: `Move every prompt of a project to the trash.
+
+Nothing is removed for good: 'pmt trash restore --project <name>' brings the
+prompts back, and 'pmt trash empty' deletes them permanently.`,
+	Example: `  pmt project delete scratch
+  pmt project delete scratch --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProjectDelete,
+}
+
+var projectRelinkCmd = &cobra.Command{
+	Use:   "relink",
+	Short: "Move prompts saved under the folder name to the current project",
+	Long: `Link prompts that were saved under a project's folder name to the
+project's stable identity.
+
+Earlier versions keyed projects by the base name of the repository folder.
+Run this inside the repository to move prompts saved under its folder name
+(or under --from, e.g. the name of the folder before it was renamed) to the
+current project's ID.`,
+	Example: `  pmt project relink
+  pmt project relink --dry-run
+  pmt project relink --from api-old`,
+	Args: cobra.NoArgs,
+	RunE: runProjectRelink,
+}
+
+func init() {
+	rootCmd.AddCommand(projectCmd)
+	projectCmd.AddCommand(projectListCmd)
+	projectCmd.AddCommand(projectRenameCmd)
+	projectCmd.AddCommand(projectMergeCmd)
+	projectCmd.AddCommand(projectDeleteCmd)
+	projectCmd.AddCommand(projectRelinkCmd)
+	projectRelinkCmd.Flags().StringVar(&projectRelinkFrom, "from", "", "Old project name to relink (default: the current folder name)")
+
+	for _, c := range []*cobra.Command{projectRenameCmd, projectMergeCmd, projectDeleteCmd, projectRelinkCmd} {
+		c.Flags().BoolVar(&projectDryRun, "dry-run", false, "Show what would change without changing anything")
+	}
+}
+
+// projectRef identifies a project by ID and display name
+type projectRef struct {
+	ID   string
+	Name string
+}
+
+// String returns "name (id)", or just the ID when they are the same
+func (r projectRef) String() string {
+	if r.Name == "" || r.Name == r.ID {
+		return r.ID
+	}
+	return r.Name + " (" + r.ID + ")"
+}
+
+// resolveProject finds a project by ID, then by display name; "." is the
+// project of the current directory
+func resolveProject(prompts []models.Prompt, ref string) (projectRef, error) {
+	if ref == "." {
+		project := currentProject()
+		return projectRef{ID: project.ID, Name: project.Name}, nil
+	}
+
+	for i := range prompts {
+		if strings.EqualFold(prompts[i].Project, ref) {
+			return projectRef{ID: prompts[i].Project, Name: prompts[i].DisplayProject()}, nil
+		}
+	}
+
+	var matches []projectRef
+	seen := make(map[string]bool)
+	for i := range prompts {
+		p := &prompts[i]
+		if strings.EqualFold(p.ProjectName, ref) && !seen[p.Project] {
+			seen[p.Project] = true
+			matches = append(matches, projectRef{ID: p.Project, Name: p.ProjectName})
+		}
+	}
+
+	if len(matches) == 0 {
+		return projectRef{}, fmt.Errorf("no prompts found in project '%s'", ref)
+	}
+	if len(matches) > 1 {
+		ids := make([]string, len(matches))
+		for i, m := range matches {
+			ids[i] = m.ID
+		}
+		return projectRef{}, fmt.Errorf("ambiguous project name %s: matches %s (use the project ID)", ref, strings.Join(ids, ", "))
+	}
+	return matches[0], nil
+}
+
+// projectFilter resolves the --project flag of a listing to a project ID,
+// failing when a display name is shared by several projects
+func projectFilter(store storage.Store, ref string) (string, error) {
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return "", fmt.Errorf("failed to load prompts: %w", err)
+	}
+	project, err := resolveProject(knownProjects(promptStore), ref)
+	if err != nil {
+		return "", err
+	}
+	return project.ID, nil
+}
+
+// projectPrompts returns the prompts that belong to the project with the given ID
+func projectPrompts(prompts []models.Prompt, id string) []*models.Prompt {
+	var matches []*models.Prompt
+	for i := range prompts {
+		if prompts[i].Project == id {
+			matches = append(matches, &prompts[i])
+		}
+	}
+	return matches
+}
+
+// printAffected lists the prompts a dry run would change
+func printAffected(prompts []*models.Prompt) {
+	for _, p := range prompts {
+		fmt.Printf("  %s %s\n", p.ID, oneLineSummary(p))
+	}
+}
+
+func runProjectList(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	if len(promptStore.Prompts) == 0 {
+		fmt.Println("No prompts found. Use 'pmt push' to add prompts.")
+		return nil
+	}
+
+	// Collect projects with counts
+	counts := make(map[string]int)
+	names := make(map[string]string)
+	for i := range promptStore.Prompts {
+		p := &promptStore.Prompts[i]
+		counts[p.Project]++
+		names[p.Project] = p.DisplayProject()
+	}
+
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if names[ids[i]] != names[ids[j]] {
+			return names[ids[i]] < names[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	current := utils.DetectGitProject()
+
+	// Print header
+	fmt.Printf("  %-20s %-8s %s\n", "Project", "Prompts", "ID")
+	fmt.Println(strings.Repeat("-", 70))
+
+	for _, id := range ids {
+		marker := " "
+		if id == current {
+			marker = "*"
+		}
+		fmt.Printf("%s %-20s %-8d %s\n", marker, truncateString(names[id], 20), counts[id], id)
+	}
+
+	fmt.Printf("\nTotal: %d project%s\n", len(ids), pluralize(len(ids)))
+	return nil
+}
+
+// projectMatch reports whether a project key and display name belong to the
+// project an operation works on; context records have no display name
+type projectMatch func(project, name string) bool
+
+// byProjectID matches everything stored under a project ID
+func byProjectID(id string) projectMatch {
+	return func(project, name string) bool { return project == id }
+}
+
+// projectItems is everything stored under a project: its prompts, its
+// prompts in the trash and its context records
+type projectItems struct {
+	prompts  []*models.Prompt
+	trashed  []*models.Prompt
+	contexts []*models.Context
+}
+
+// collectProject returns the items of a loaded store that match a project
+func collectProject(s *models.PromptStore, match projectMatch) projectItems {
+	var items projectItems
+	for i := range s.Prompts {
+		if match(s.Prompts[i].Project, s.Prompts[i].ProjectName) {
+			items.prompts = append(items.prompts, &s.Prompts[i])
+		}
+	}
+	for i := range s.Trash {
+		if match(s.Trash[i].Project, s.Trash[i].ProjectName) {
+			items.trashed = append(items.trashed, &s.Trash[i])
+		}
+	}
+	for i := range s.Contexts {
+		if match(s.Contexts[i].Project, "") {
+			items.contexts = append(items.contexts, &s.Contexts[i])
+		}
+	}
+	return items
+}
+
+func (items projectItems) empty() bool {
+	return len(items.prompts) == 0 && len(items.trashed) == 0 && len(items.contexts) == 0
+}
+
+// String counts the items, e.g. "3 prompts, 1 trashed prompt, 2 context records"
+func (items projectItems) String() string {
+	summary := fmt.Sprintf("%d prompt%s", len(items.prompts), pluralize(len(items.prompts)))
+	if n := len(items.trashed); n > 0 {
+		summary += fmt.Sprintf(", %d trashed prompt%s", n, pluralize(n))
+	}
+	if n := len(items.contexts); n > 0 {
+		summary += fmt.Sprintf(", %d context record%s", n, pluralize(n))
+	}
+	return summary
+}
+
+// knownProjects returns the prompts to resolve project references against,
+// with stubs for projects that only have trashed prompts or context records
+func knownProjects(s *models.PromptStore) []models.Prompt {
+	prompts := append([]models.Prompt{}, s.Prompts...)
+	prompts = append(prompts, s.Trash...)
+	for _, c := range s.Contexts {
+		prompts = append(prompts, models.Prompt{Project: c.Project})
+	}
+	return prompts
+}
+
+// rekeyProject moves the prompts, trashed prompts and context records that
+// match to the project key id. update sets the project fields of a prompt;
+// records moved onto a context the project already has are merged into it.
+func rekeyProject(s *models.PromptStore, match projectMatch, id string, update func(*models.Prompt)) projectItems {
+	items := collectProject(s, match)
+	for _, p := range items.prompts {
+		update(p)
+	}
+	for _, p := range items.trashed {
+		update(p)
+	}
+
+	// Records that stay go first, so moved ones merge into them
+	var records, moved []models.Context
+	for _, c := range s.Contexts {
+		if !match(c.Project, "") || c.Project == id {
+			records = append(records, c)
+			continue
+		}
+		c.Project = id
+		moved = append(moved, c)
+	}
+	for _, c := range moved {
+		records = appendContext(records, c)
+	}
+	s.Contexts = records
+	return items
+}
+
+// renameProject changes the display name of a project. Prompts saved by
+// earlier versions are keyed by the folder name; for those the key itself,
+// and so their context records, are renamed.
+func renameProject(s *models.PromptStore, id, newName string) projectItems {
+	items := collectProject(s, byProjectID(id))
+	key := id
+	for _, p := range append(items.prompts, items.trashed...) {
+		if p.ProjectName == "" {
+			key = newName
+		}
+	}
+	return rekeyProject(s, byProjectID(id), key, func(p *models.Prompt) {
+		if p.ProjectName == "" {
+			p.Project = newName
+		} else {
+			p.ProjectName = newName
+		}
+	})
+}
+
+// mergeProject moves everything of the project from into the project into
+func mergeProject(s *models.PromptStore, from string, into projectRef) projectItems {
+	return rekeyProject(s, byProjectID(from), into.ID, func(p *models.Prompt) {
+		p.Project = into.ID
+		if into.Name != into.ID {
+			p.ProjectName = into.Name
+		} else {
+			p.ProjectName = ""
+		}
+	})
+}
+
+// legacyProject matches everything saved by earlier versions under a folder
+// name instead of a stable project ID
+func legacyProject(folder string) projectMatch {
+	return func(project, name string) bool {
+		return name == "" && strings.EqualFold(project, folder)
+	}
+}
+
+// relinkProject keys the prompts and context records saved under a folder
+// name by the stable ID of the project
+func relinkProject(s *models.PromptStore, from string, project utils.Project) projectItems {
+	return rekeyProject(s, legacyProject(from), project.ID, func(p *models.Prompt) {
+		p.Project = project.ID
+		p.ProjectName = project.Name
+	})
+}
+
+// deleteProject moves the prompts of a project to the trash and removes its
+// context records
+func deleteProject(s *models.PromptStore, id string) projectItems {
+	items := collectProject(s, byProjectID(id))
+	remove := make(map[int]bool)
+	for i := range s.Prompts {
+		if s.Prompts[i].Project == id {
+			remove[i] = true
+		}
+	}
+	storage.MoveToTrash(s, remove)
+
+	var records []models.Context
+	for _, c := range s.Contexts {
+		if c.Project != id {
+			records = append(records, c)
+		}
+	}
+	s.Contexts = records
+	return items
+}
+
+func runProjectRename(cmd *cobra.Command, args []string) error {
+	newName := strings.TrimSpace(args[1])
+	if newName == "" {
+		return fmt.Errorf("new project name cannot be empty")
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	project, err := resolveProject(knownProjects(promptStore), args[0])
+	if err != nil {
+		return err
+	}
+	if project.Name == newName {
+		return fmt.Errorf("old and new project names are the same")
+	}
+
+	affected := collectProject(promptStore, byProjectID(project.ID))
+	if affected.empty() {
+		return fmt.Errorf("no prompts found in project '%s'", args[0])
+	}
+
+	if projectDryRun {
+		fmt.Printf("Would rename project %s → %s (%s):\n", project, newName, affected)
+		printAffected(affected.prompts)
+		return nil
+	}
+
+	var renamed projectItems
+	err = store.Modify(func(s *models.PromptStore) error {
+		renamed = renameProject(s, project.ID, newName)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename project: %w", err)
+	}
+
+	fmt.Printf("✓ Renamed project: %s → %s\n", project.Name, newName)
+	fmt.Printf("  Updated %s\n", renamed)
+	return nil
+}
+
+func runProjectMerge(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	known := knownProjects(promptStore)
+	from, err := resolveProject(known, args[0])
+	if err != nil {
+		return err
+	}
+	into, err := resolveProject(known, args[1])
+	if err != nil {
+		return err
+	}
+	if from.ID == into.ID {
+		return fmt.Errorf("cannot merge project %s into itself", from)
+	}
+
+	affected := collectProject(promptStore, byProjectID(from.ID))
+	if affected.empty() {
+		return fmt.Errorf("no prompts found in project '%s'", args[0])
+	}
+
+	if projectDryRun {
+		fmt.Printf("Would move %s from %s into %s:\n", affected, from, into)
+		printAffected(affected.prompts)
+		return nil
+	}
+
+	var moved projectItems
+	err = store.Modify(func(s *models.PromptStore) error {
+		moved = mergeProject(s, from.ID, into)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to merge projects: %w", err)
+	}
+
+	fmt.Printf("✓ Merged project %s into %s\n", from, into)
+	fmt.Printf("  Moved %s\n", moved)
+	return nil
+}
+
+func runProjectDelete(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	project, err := resolveProject(knownProjects(promptStore), args[0])
+	if err != nil {
+		return err
+	}
+
+	affected := collectProject(promptStore, byProjectID(project.ID))
+	if len(affected.prompts) == 0 && len(affected.contexts) == 0 {
+		return fmt.Errorf("no prompts found in project '%s'", args[0])
+	}
+
+	if projectDryRun {
+		fmt.Printf("Would move %d prompt%s of project %s to the trash", len(affected.prompts), pluralize(len(affected.prompts)), project)
+		if n := len(affected.contexts); n > 0 {
+			fmt.Printf(" and remove %d context record%s", n, pluralize(n))
+		}
+		fmt.Println(":")
+		printAffected(affected.prompts)
+		return nil
+	}
+
+	var deleted projectItems
+	err = store.Modify(func(s *models.PromptStore) error {
+		deleted = deleteProject(s, project.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+
+	fmt.Printf("✓ Moved %d prompt%s of project %s to the trash\n", len(deleted.prompts), pluralize(len(deleted.prompts)), project)
+	if n := len(deleted.contexts); n > 0 {
+		fmt.Printf("  Removed %d context record%s\n", n, pluralize(n))
+	}
+	if len(deleted.prompts) > 0 {
+		fmt.Printf("  Restore them with: pmt trash restore --project %s\n", project.ID)
+	}
+	return nil
+}
+
+func runProjectRelink(cmd *cobra.Command, args []string) error {
+	project := utils.DetectProject()
+	if project.ID == utils.NoProject {
+		return fmt.Errorf("not in a git repository")
+	}
+
+	from := projectRelinkFrom
+	if from == "" {
+		from = project.Name
+	}
+	if from == project.ID {
+		return fmt.Errorf("project %s is already keyed by %s", project.Name, project.ID)
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	affected := collectProject(promptStore, legacyProject(from))
+	if affected.empty() {
+		fmt.Printf("No prompts saved under project name '%s'\n", from)
+		return nil
+	}
+
+	if projectDryRun {
+		fmt.Printf("Would relink %s from %s to %s (%s):\n", affected, from, project.Name, project.ID)
+		printAffected(affected.prompts)
+		return nil
+	}
+
+	var relinked projectItems
+	err = store.Modify(func(s *models.PromptStore) error {
+		relinked = relinkProject(s, from, project)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to relink prompts: %w", err)
+	}
+
+	fmt.Printf("✓ Relinked project: %s → %s (%s)\n", from, project.Name, project.ID)
+	fmt.Printf("  Updated %s\n", relinked)
+	return nil
+}
+
+// isLegacyProject reports whether a prompt is keyed by the folder name
+// instead of a stable project ID
+func isLegacyProject(p *models.Prompt, name string) bool {
+	return legacyProject(name)(p.Project, p.ProjectName)
+}
+
+// oneLineSummary returns a prompt's name, or the start of its content
+func oneLineSummary(p *models.Prompt) string {
+	if p.Name != "" {
+		return "[" + p.Name + "]"
+	}
+	return truncateString(strings.Join(strings.Fields(p.Text()), " "), 50)
+}
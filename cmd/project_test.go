@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+func TestMergeProjectMovesContextRecords(t *testing.T) {
+	s := &models.PromptStore{
+		Prompts: []models.Prompt{
+			{ID: "a", Project: "old", ProjectName: "api-old", Context: "backend"},
+			{ID: "b", Project: "new", ProjectName: "api", Context: "backend"},
+			{ID: "c", Project: "other", ProjectName: "other"},
+		},
+		Trash: []models.Prompt{{ID: "d", Project: "old", ProjectName: "api-old"}},
+		Contexts: []models.Context{
+			{Path: "backend", Project: "old", Description: "Old backend", Tags: []string{"go"}},
+			{Path: "docs", Project: "old", Type: "writing"},
+			{Path: "backend", Project: "new", Type: "code", Tags: []string{"api"}},
+			{Path: "docs", Project: "other"},
+		},
+	}
+
+	moved := mergeProject(s, "old", projectRef{ID: "new", Name: "api"})
+	if got := moved.String(); got != "1 prompt, 1 trashed prompt, 2 context records" {
+		t.Errorf("moved = %q", got)
+	}
+
+	projects := func(prompts []models.Prompt) []string {
+		var got []string
+		for _, p := range prompts {
+			got = append(got, p.Project+"/"+p.ProjectName)
+		}
+		return got
+	}
+	want := []models.Context{
+		{Path: "backend", Project: "new", Description: "Old backend", Type: "code", Tags: []string{"api", "go"}},
+		{Path: "docs", Project: "other"},
+		{Path: "docs", Project: "new", Type: "writing"},
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"prompts", projects(s.Prompts), []string{"new/api", "new/api", "other/other"}},
+		{"trash", projects(s.Trash), []string{"new/api"}},
+		{"context records", s.Contexts, want},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %+v, want %+v", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/utils"
+)
+
+var (
+	pruneMerged bool
+	pruneInto   string
+	pruneDryRun bool
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune --merged",
+	Short: "Archive prompts of merged or deleted branches",
+	Long: `Archive the current project's prompts whose branch has been merged into the
+default branch, or no longer exists locally or on origin.
+
+Archived prompts are kept but no longer offered by apply, pop and list.
+See them with 'pmt list --archived' and bring one back with
+'pmt archive <id> --unset'.
+
+The default branch is origin's HEAD, or else main or master; use --into to
+name another one. A branch counts as merged once it has commits made after the
+prompt was pushed and they are all on the default branch, so a new branch
+without commits of its own is kept. Branches merged with squash or rebase, or
+without new commits since the prompt was pushed, are only detected once they
+have been deleted.`,
+	Example: `  pmt prune --merged
+  pmt prune --merged --dry-run
+  pmt prune --merged --into develop`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+	pruneCmd.Flags().BoolVar(&pruneMerged, "merged", false, "Archive prompts of merged or deleted branches")
+	pruneCmd.Flags().StringVar(&pruneInto, "into", "", "Branch the others are merged into (default: origin's HEAD, main or master)")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be archived without changing anything")
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	if !pruneMerged {
+		return fmt.Errorf("nothing to prune: pass --merged to archive prompts of merged or deleted branches")
+	}
+
+	project := utils.DetectProject()
+	if project.ID == utils.NoProject {
+		return fmt.Errorf("not in a git repository")
+	}
+
+	base := pruneInto
+	if base == "" {
+		var err error
+		base, err = utils.GitDefaultBranch()
+		if err != nil {
+			return fmt.Errorf("%w; pass it with --into", err)
+		}
+	}
+
+	merged, err := utils.GitBranches(base)
+	if err != nil {
+		return fmt.Errorf("failed to list branches merged into %s: %w", base, err)
+	}
+	existing, err := utils.GitBranches("")
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	// The branch others are merged into, and the one being worked on, stay
+	branches := branchState{
+		project:  project.ID,
+		base:     base,
+		keep:     map[string]bool{strings.TrimPrefix(base, "origin/"): true, utils.GitBranch(): true},
+		merged:   merged,
+		existing: existing,
+	}
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	var affected []*models.Prompt
+	for i := range promptStore.Prompts {
+		p := &promptStore.Prompts[i]
+		if branches.reason(p) != "" {
+			affected = append(affected, p)
+		}
+	}
+
+	if len(affected) == 0 {
+		fmt.Println("No prompts of merged or deleted branches.")
+		return nil
+	}
+
+	if pruneDryRun {
+		fmt.Printf("Would archive %d prompt%s:\n", len(affected), pluralize(len(affected)))
+	}
+	for _, p := range affected {
+		fmt.Printf("  %s %-20s %s (%s)\n", p.ID, truncateString(p.Branch, 20), oneLineSummary(p), branches.reason(p))
+	}
+	if pruneDryRun {
+		return nil
+	}
+
+	err = store.BulkUpdate(func(p *models.Prompt) bool {
+		if branches.reason(p) == "" {
+			return false
+		}
+		p.Archived = true
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive prompts: %w", err)
+	}
+
+	fmt.Printf("✓ Archived %d prompt%s\n", len(affected), pluralize(len(affected)))
+	fmt.Println("💡 List them with 'pmt list --archived'")
+	return nil
+}
+
+// branchState is what prune knows about the branches of a project
+type branchState struct {
+	project  string
+	base     string            // branch the others are merged into
+	keep     map[string]bool   // branches whose prompts are never pruned
+	merged   map[string]string // branches merged into base, with their commit
+	existing map[string]string // every local and origin branch, with its commit
+}
+
+// reason returns why a prompt would be archived, or "" to keep it. A branch
+// whose commit is on base only counts as merged once it has moved past the
+// commit the prompt was pushed at: a new branch without commits of its own
+// is on base too, but has not been merged.
+func (b branchState) reason(p *models.Prompt) string {
+	if p.Project != b.project || p.Branch == "" || p.Archived || b.keep[p.Branch] {
+		return ""
+	}
+	if commit, ok := b.merged[p.Branch]; ok && p.Commit != "" && commit != p.Commit {
+		return "merged into " + b.base
+	}
+	if _, ok := b.existing[p.Branch]; !ok {
+		return "deleted"
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+func TestBranchStateReason(t *testing.T) {
+	branches := branchState{
+		project: "github.com/org/api",
+		base:    "main",
+		keep:    map[string]bool{"main": true, "current": true},
+		merged: map[string]string{
+			"main":    "c0",
+			"merged":  "c2",
+			"fresh":   "c0",
+			"current": "c0",
+		},
+		existing: map[string]string{
+			"main":    "c0",
+			"merged":  "c2",
+			"fresh":   "c0",
+			"open":    "c3",
+			"current": "c0",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		prompt models.Prompt
+		want   string
+	}{
+		{
+			name:   "branch merged after the prompt was pushed",
+			prompt: models.Prompt{Project: "github.com/org/api", Branch: "merged", Commit: "c1"},
+			want:   "merged into main",
+		},
+		{
+			name:   "new branch without commits of its own is kept",
+			prompt: models.Prompt{Project: "github.com/org/api", Branch: "fresh", Commit: "c0"},
+			want:   "",
+		},
+		{
+			name:   "merged branch without a recorded commit is kept",
+			prompt: models.Prompt{Project: "github.com/org/api", Branch: "merged"},
+			want:   "",
+		},
+		{
+			name:   "unmerged branch is kept",
+			prompt: models.Prompt{Project: "github.com/org/api", Branch: "open", Commit: "c1"},
+			want:   "",
+		},
+		{
+			name:   "deleted branch",
+			prompt: models.Prompt{Project: "github.com/org/api", Branch: "gone", Commit: "c1"},
+			want:   "deleted",
+		},
+		{
+			name:   "deleted branch without a recorded commit",
+			prompt: models.Prompt{Project: "github.com/org/api", Branch: "gone"},
+			want:   "deleted",
+		},
+		{
+			name:   "base branch is kept",
+			prompt: models.Prompt{Project: "github.com/org/api", Branch: "main", Commit: "b0"},
+			want:   "",
+		},
+		{
+			name:   "current branch is kept",
+			prompt: models.Prompt{Project: "github.com/org/api", Branch: "current", Commit: "b0"},
+			want:   "",
+		},
+		{
+			name:   "prompt without a branch is kept",
+			prompt: models.Prompt{Project: "github.com/org/api"},
+			want:   "",
+		},
+		{
+			name:   "other projects are left alone",
+			prompt: models.Prompt{Project: "github.com/org/web", Branch: "gone", Commit: "c1"},
+			want:   "",
+		},
+		{
+			name:   "archived prompts are left alone",
+			prompt: models.Prompt{Project: "github.com/org/api", Branch: "gone", Archived: true},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := branches.reason(&tt.prompt); got != tt.want {
+				t.Errorf("reason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
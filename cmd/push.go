@@ -9,16 +9,23 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/config"
 	"github.com/sunny/pmt/internal/models"
 	"github.com/sunny/pmt/internal/storage"
 	"github.com/sunny/pmt/internal/utils"
 )
 
 var (
-	pushType    string
-	pushName    string
-	pushContext string
-	pushTags    []string
+	pushType     string
+	pushName     string
+	pushContext  string
+	pushTags     []string
+	pushExtends  string
+	pushVars     map[string]string
+	pushChat     bool
+	pushSystem   string
+	pushGlobal   bool
+	pushNoBranch bool
 )
 
 var pushCmd = &cobra.Command{
@@ -28,72 +35,188 @@ var pushCmd = &cobra.Command{
 
 If no content is provided, an editor will open for you to write a longer prompt.
 The prompt will be tagged with the current git project automatically.
-You can optionally specify a type and tags.`,
+You can optionally specify a type and tags.
+
+Inside a subdirectory of a git repository, the context defaults to the
+directory's path relative to the repository root, e.g. services/billing.
+Rules in the repository's .pmt.yaml can map directories to other contexts:
+
+  contexts:
+    - path: services/billing
+      context: billing
+
+Set 'infer_context: false' there to turn this off, or pass -c "" to save a
+single prompt without a context.
+
+The repository's .pmt.yaml can also set a default context for the root,
+tags added to every prompt, and the types allowed in the repository.
+
+The type must be registered (see 'pmt type list'). Without content, the
+editor opens on the type's template, if it has one.
+
+Pushing into a context created with 'pmt context create' applies its default
+type (unless --type is given) and adds its default tags.
+
+With --extends, the new prompt inherits the content of a base prompt. Its own
+{{section:name}}...{{/section}} blocks replace the base's sections of the same
+name, any other text is appended, and --var values override the base's.
+
+With --chat, the content is split into messages at role marker lines
+([system], [user], [assistant]). --system adds a system message and implies --chat.
+
+With --global, the prompt is offered in every project, not only the one it
+was saved in. Use 'pmt global' to change this later.
+
+The current branch and HEAD commit are recorded with the prompt, and apply
+and pop offer it only on that branch, like a stash entry. Use --no-branch for
+prompts that apply to every branch.`,
 	Example: `  pmt push "Fix memory leak in async handler"
   pmt push "Add OAuth login" -t feature --tags auth,api
   pmt push "Refactor error handling" -t refactor
+  pmt push --extends review --var lang=rust "{{section:focus}}Check unsafe blocks{{/section}}"
+  pmt push --system "You are a senior Go reviewer" "Review this diff"
+  pmt push -c "" "Applies to the whole repository"
+  pmt push --no-branch -n review "Review this diff for error handling"
+  pmt push --global -n style-guide "Prefer small, focused functions"
+  pmt push --chat   # Opens editor; separate turns with [system], [user], [assistant]
   pmt push   # Opens editor for longer prompts`,
 	RunE: runPush,
 }
 
 func init() {
 	rootCmd.AddCommand(pushCmd)
-	pushCmd.Flags().StringVarP(&pushType, "type", "t", "general", "Type: bugfix, feature, refactor, test, general")
+	pushCmd.Flags().StringVarP(&pushType, "type", "t", "", "Type, one of 'pmt type list' (default: the default_type setting, or general)")
 	pushCmd.Flags().StringVarP(&pushName, "name", "n", "", "Custom name/title for the prompt")
-	pushCmd.Flags().StringVarP(&pushContext, "context", "c", "", "Context within the project")
+	pushCmd.Flags().StringVarP(&pushContext, "context", "c", "", "Context within the project (default: inferred from the current directory)")
 	pushCmd.Flags().StringSliceVarP(&pushTags, "tags", "g", []string{}, "Tags (comma-separated)")
+	pushCmd.Flags().StringVar(&pushExtends, "extends", "", "Name or ID of a base prompt to inherit from")
+	pushCmd.Flags().StringToStringVar(&pushVars, "var", nil, "Set a template variable (name=value)")
+	pushCmd.Flags().BoolVar(&pushChat, "chat", false, "Parse content into role-tagged chat messages")
+	pushCmd.Flags().BoolVar(&pushGlobal, "global", false, "Show the prompt in every project")
+	pushCmd.Flags().BoolVar(&pushNoBranch, "no-branch", false, "Don't tie the prompt to the current branch")
+	pushCmd.Flags().StringVar(&pushSystem, "system", "", "System message for a chat prompt (implies --chat)")
 }
 
 func runPush(cmd *cobra.Command, args []string) error {
-	var content string
-
-	// If no args provided, open editor
-	if len(args) == 0 {
-		var err error
-		content, err = openEditor()
-		if err != nil {
-			return fmt.Errorf("failed to open editor: %w", err)
-		}
-	} else {
-		content = strings.Join(args, " ")
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	repo, err := config.CurrentRepo()
+	if err != nil {
+		return err
 	}
 
-	// Trim whitespace
-	content = strings.TrimSpace(content)
-
-	if content == "" {
-		return fmt.Errorf("prompt content cannot be empty")
+	// Create the store
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
 	}
 
-	// Validate type
-	validTypes := map[string]bool{
-		"bugfix":   true,
-		"feature":  true,
-		"refactor": true,
-		"test":     true,
-		"general":  true,
+	// Without --type, use the configured default type, or the repository's
+	// first type if it doesn't allow that
+	if !cmd.Flags().Changed("type") {
+		pushType = cfg.PromptType()
+		if !repo.AllowsType(pushType) {
+			pushType = repo.Types[0]
+		}
+	}
+	if pushType, err = validateType(promptStore, pushType); err != nil {
+		return err
 	}
 
-	if !validTypes[pushType] {
-		return fmt.Errorf("invalid type: %s (must be bugfix, feature, refactor, test, or general)", pushType)
+	// Resolve the base prompt so the stored reference is a stable ID
+	extends := ""
+	if pushExtends != "" {
+		base, err := findPrompt(store, pushExtends)
+		if err != nil {
+			return fmt.Errorf("failed to resolve base prompt: %w", err)
+		}
+		extends = base.ID
 	}
 
-	// Create the store
-	store, err := storage.NewFileStore()
-	if err != nil {
-		return fmt.Errorf("failed to create store: %w", err)
+	// Default to the context of the current directory
+	if !cmd.Flags().Changed("context") {
+		pushContext, err = inferContext()
+		if err != nil {
+			return err
+		}
 	}
 
 	// Create the prompt
+	project := currentProject()
 	prompt := &models.Prompt{
 		ID:        utils.GenerateID(),
 		Name:      pushName,
-		Content:   content,
 		Type:      pushType,
-		Project:   utils.DetectGitProject(),
-		Context:   pushContext,
+		Project:   project.ID,
+		Context:   models.NormalizeContext(pushContext),
 		Tags:      pushTags,
 		CreatedAt: time.Now(),
+		Extends:   extends,
+		Vars:      pushVars,
+		Global:    pushGlobal,
+
+		ProjectName: project.Name,
+	}
+	if !pushNoBranch {
+		prompt.Branch = utils.GitBranch()
+		prompt.Commit = utils.GitHead()
+	}
+
+	// Apply the type and tags of the context, if it has a record, and the
+	// repository's tags
+	if err := applyContextDefaults(store, prompt, cmd.Flags().Changed("type")); err != nil {
+		return err
+	}
+	for _, tag := range repo.Tags {
+		if !containsFold(prompt.Tags, tag) {
+			prompt.Tags = append(prompt.Tags, tag)
+		}
+	}
+
+	// If no args provided, open editor on the type's template
+	var content string
+	if len(args) == 0 {
+		content, err = openEditor(templateFor(promptStore, prompt.Type))
+		if err != nil {
+			return fmt.Errorf("failed to open editor: %w", err)
+		}
+	} else {
+		content = strings.Join(args, " ")
+	}
+
+	// Trim whitespace
+	content = strings.TrimSpace(content)
+
+	// A prompt that extends another may consist of overrides only
+	if content == "" && pushExtends == "" {
+		return fmt.Errorf("prompt content cannot be empty")
+	}
+
+	// Chat prompts are stored as a list of turns
+	if pushChat || pushSystem != "" {
+		if pushExtends != "" {
+			return fmt.Errorf("a chat prompt cannot extend another prompt")
+		}
+		var messages []models.Message
+		if pushSystem != "" {
+			messages = append(messages, models.Message{Role: models.RoleSystem, Content: strings.TrimSpace(pushSystem)})
+		}
+		if content != "" {
+			parsed, err := models.ParseMessages(content)
+			if err != nil {
+				return err
+			}
+			messages = append(messages, parsed...)
+		}
+		prompt.Messages = messages
+	} else {
+		prompt.Content = content
 	}
 
 	// Save the prompt
@@ -101,38 +224,98 @@ func runPush(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to save prompt: %w", err)
 	}
 
-	fmt.Printf("✓ Saved prompt: %s (%s) in project: %s\n", prompt.ID, prompt.Type, prompt.Project)
+	if prompt.Context != "" {
+		fmt.Printf("✓ Saved prompt: %s (%s) in project: %s, context: %s\n", prompt.ID, prompt.Type, prompt.DisplayProject(), prompt.Context)
+	} else {
+		fmt.Printf("✓ Saved prompt: %s (%s) in project: %s\n", prompt.ID, prompt.Type, prompt.DisplayProject())
+	}
 	return nil
 }
 
-// openEditor opens the user's preferred editor to write a prompt
-func openEditor() (string, error) {
-	// Get editor from environment, default to vim
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = os.Getenv("VISUAL")
+// validateType checks that a prompt type is registered and allowed by the
+// repository's .pmt.yaml, and returns its registered name
+func validateType(promptStore *models.PromptStore, t string) (string, error) {
+	t, err := promptStore.ValidateType(t)
+	if err != nil {
+		return "", err
+	}
+
+	repo, err := config.CurrentRepo()
+	if err != nil {
+		return "", err
 	}
-	if editor == "" {
-		editor = "vim"
+	if !repo.AllowsType(t) {
+		return "", fmt.Errorf("type %s is not allowed in this repository (allowed: %s)", t, strings.Join(repo.Types, ", "))
 	}
+	return t, nil
+}
 
-	// Create a temporary file
-	tmpDir := os.TempDir()
-	tmpFile := filepath.Join(tmpDir, fmt.Sprintf("pmt-prompt-%d.md", time.Now().Unix()))
+// templateFor returns the template of a registered type, or ""
+func templateFor(promptStore *models.PromptStore, name string) string {
+	if t := promptStore.FindType(name); t != nil {
+		return t.Template
+	}
+	return ""
+}
+
+// openEditor opens the user's preferred editor to write a prompt, starting
+// from the given template
+func openEditor(initial string) (string, error) {
+	if initial != "" && !strings.HasSuffix(initial, "\n") {
+		initial += "\n"
+	}
 
 	// Write initial template
-	template := `# Write your prompt below this line
-# Lines starting with # will be ignored
+	template := `# Write your prompt below this comment block
+# Only these first lines starting with # are removed; later ones, such as
+# markdown headings, are part of the prompt
 # Save and close the editor to save the prompt
 
-`
-	if err := os.WriteFile(tmpFile, []byte(template), 0644); err != nil {
+` + initial
+	data, err := editText(template)
+	if err != nil {
+		return "", err
+	}
+
+	return stripHeader(data), nil
+}
+
+// stripHeader drops the comment block at the top of an edited prompt and
+// keeps the rest as written
+func stripHeader(data string) string {
+	lines := strings.Split(data, "\n")
+	start := 0
+	for start < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[start]), "#") {
+		start++
+	}
+	return strings.TrimSpace(strings.Join(lines[start:], "\n"))
+}
+
+// editText opens the user's preferred editor on the given text and returns
+// the edited result unchanged
+func editText(initial string) (string, error) {
+	// The editor setting, or $EDITOR, $VISUAL and vim. An invalid config
+	// file must not keep 'pmt config edit' from fixing it.
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+	editor, err := cfg.EditorCommand()
+	if err != nil {
+		return "", fmt.Errorf("invalid editor setting: %w", err)
+	}
+
+	// Create a temporary file
+	tmpDir := os.TempDir()
+	tmpFile := filepath.Join(tmpDir, fmt.Sprintf("pmt-prompt-%d.md", time.Now().UnixNano()))
+
+	if err := os.WriteFile(tmpFile, []byte(initial), 0644); err != nil {
 		return "", fmt.Errorf("failed to create temp file: %w", err)
 	}
 	defer os.Remove(tmpFile)
 
 	// Open editor
-	cmd := exec.Command(editor, tmpFile)
+	cmd := exec.Command(editor[0], append(editor[1:], tmpFile)...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -146,16 +329,5 @@ func openEditor() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to read temp file: %w", err)
 	}
-
-	// Parse content (remove comment lines)
-	lines := strings.Split(string(data), "\n")
-	var contentLines []string
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if !strings.HasPrefix(trimmed, "#") && trimmed != "" {
-			contentLines = append(contentLines, line)
-		}
-	}
-
-	return strings.TrimSpace(strings.Join(contentLines, "\n")), nil
+	return string(data), nil
 }
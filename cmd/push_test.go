@@ -0,0 +1,29 @@
+package cmd
+
+import "testing"
+
+func TestStripHeader(t *testing.T) {
+	header := "# Write your prompt below this comment block\n# Save and close the editor to save the prompt\n\n"
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", header, ""},
+		{"plain text", header + "Fix the bug\n", "Fix the bug"},
+		{"markdown headings of a template are kept", header + "# Review\n\n## Focus\nCorrectness\n", "# Review\n\n## Focus\nCorrectness"},
+		{"blank lines inside the prompt are kept", header + "First\n\n\nSecond\n", "First\n\n\nSecond"},
+		{"indentation is kept", header + "Steps:\n  - one\n  - two\n", "Steps:\n  - one\n  - two"},
+		{"header without a blank line", "# only a comment\nText", "Text"},
+		{"CRLF line endings", "# header\r\n\r\n# Title\r\nText\r\n", "# Title\r\nText"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripHeader(tt.data); got != tt.want {
+				t.Errorf("stripHeader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
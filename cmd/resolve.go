@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/ui"
+	"github.com/sunny/pmt/internal/utils"
+)
+
+// stashIndexPattern matches git-stash-style references: "0", "@{1}" or "stash@{2}"
+var stashIndexPattern = regexp.MustCompile(`^(?:(?:stash)?@\{(\d+)\}|(\d{1,3}))$`)
+
+// findPrompt looks a prompt up by name first, then by ID or ID prefix. A
+// name shared by several prompts is an error rather than an ID prefix.
+func findPrompt(store storage.Store, ref string) (*models.Prompt, error) {
+	p, err := store.FindByName(ref)
+	if err == nil {
+		return p, nil
+	}
+	if errors.Is(err, storage.ErrAmbiguous) {
+		return nil, err
+	}
+	return store.FindByID(ref)
+}
+
+// setTypeColors passes the colors of the registered types to the selectors.
+// Only commands that draw selectors call it; the colors are cosmetic, so a
+// store that cannot be read leaves the defaults.
+func setTypeColors(store storage.Store) {
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return
+	}
+
+	colors := make(map[string]string)
+	for _, t := range promptStore.TypeRegistry() {
+		colors[strings.ToLower(t.Name)] = t.Color
+	}
+	ui.SetTypeColors(colors)
+}
+
+// choosePrompts picks one prompt, or several when opts.Multi is set, by
+// reference when args are given and interactively otherwise. Picker actions in
+// opts are available while selecting. The boolean reports whether the selector
+// was used.
+func choosePrompts(prompts []models.Prompt, args []string, opts ui.PickerOptions) ([]*models.Prompt, bool, error) {
+	if !opts.Multi && len(args) > 1 {
+		return nil, false, fmt.Errorf("too many arguments; use --multi to select several prompts")
+	}
+
+	if len(args) > 0 {
+		selected := make([]*models.Prompt, 0, len(args))
+		for _, ref := range args {
+			p, err := resolvePrompt(prompts, ref)
+			if err != nil {
+				return nil, false, err
+			}
+			selected = append(selected, p)
+		}
+		return selected, false, nil
+	}
+
+	if !opts.Multi && len(opts.Actions) == 0 {
+		selected, interactive, err := choosePrompt(prompts, nil)
+		if err != nil {
+			return nil, interactive, err
+		}
+		return []*models.Prompt{selected}, interactive, nil
+	}
+
+	chosen, err := ui.PickPrompts(prompts, opts)
+	if err != nil {
+		return nil, true, fmt.Errorf("selection cancelled or failed: %w", err)
+	}
+
+	// Actions may have changed the list, so use the picker's copies
+	selected := make([]*models.Prompt, len(chosen))
+	for i := range chosen {
+		selected[i] = &chosen[i]
+	}
+	return selected, true, nil
+}
+
+// choosePrompt picks a prompt from prompts: by reference when args holds one,
+// interactively otherwise. The boolean reports whether the selector was used.
+func choosePrompt(prompts []models.Prompt, args []string) (*models.Prompt, bool, error) {
+	if len(args) > 0 {
+		selected, err := resolvePrompt(prompts, args[0])
+		return selected, false, err
+	}
+
+	selected, err := ui.SelectPrompt(prompts)
+	if err != nil {
+		return nil, true, fmt.Errorf("selection cancelled or failed: %w", err)
+	}
+	return selected, true, nil
+}
+
+// chooseVariant returns the variant to render. When none was requested and the
+// prompt has variants, the user picks one (or the default content) interactively.
+func chooseVariant(p *models.Prompt, requested string) (string, error) {
+	if requested != "" || len(p.Variants) == 0 {
+		return requested, nil
+	}
+
+	variant, err := ui.SelectVariant(p)
+	if err != nil {
+		return "", fmt.Errorf("selection cancelled or failed: %w", err)
+	}
+	return variant, nil
+}
+
+// resolvePrompt finds a prompt among prompts by stash index, name, or ID prefix.
+// Stash indices count from the most recent prompt of the current project, so
+// "0" is the prompt pushed last, like stash@{0}. A bare number that is the
+// unique prefix of an ID refers to that prompt; stash@{N} is always an index.
+func resolvePrompt(prompts []models.Prompt, ref string) (*models.Prompt, error) {
+	if m := stashIndexPattern.FindStringSubmatch(ref); m != nil {
+		if m[2] != "" {
+			if byID := matchIDPrefix(prompts, ref); len(byID) == 1 {
+				return byID[0], nil
+			}
+		}
+		digits := m[1] + m[2]
+		index, _ := strconv.Atoi(digits)
+
+		stack := stackOrder(prompts, utils.DetectGitProject())
+		if index >= len(stack) {
+			return nil, fmt.Errorf("no prompt at index %d (%d prompt%s in the current project)", index, len(stack), pluralize(len(stack)))
+		}
+		return stack[index], nil
+	}
+
+	var byName []*models.Prompt
+	for i := range prompts {
+		if prompts[i].Name != "" && strings.EqualFold(prompts[i].Name, ref) {
+			byName = append(byName, &prompts[i])
+		}
+	}
+	if len(byName) == 1 {
+		return byName[0], nil
+	}
+	if len(byName) > 1 {
+		return nil, fmt.Errorf("ambiguous name %s: matches %s", ref, joinIDs(byName))
+	}
+
+	byID := matchIDPrefix(prompts, ref)
+	if len(byID) == 0 {
+		return nil, fmt.Errorf("no prompt matches %s", ref)
+	}
+	if len(byID) > 1 {
+		return nil, fmt.Errorf("ambiguous ID %s: matches %s", ref, joinIDs(byID))
+	}
+	return byID[0], nil
+}
+
+// matchIDPrefix returns the prompts whose ID starts with prefix
+func matchIDPrefix(prompts []models.Prompt, prefix string) []*models.Prompt {
+	var matches []*models.Prompt
+	for i := range prompts {
+		if utils.MatchIDPrefix(prompts[i].ID, prefix) {
+			matches = append(matches, &prompts[i])
+		}
+	}
+	return matches
+}
+
+// stackOrder returns the prompts of a project, most recently pushed first
+func stackOrder(prompts []models.Prompt, project string) []*models.Prompt {
+	// Prompts are stored in push order, so walking backwards breaks timestamp ties
+	var stack []*models.Prompt
+	for i := len(prompts) - 1; i >= 0; i-- {
+		if strings.EqualFold(prompts[i].Project, project) {
+			stack = append(stack, &prompts[i])
+		}
+	}
+
+	sort.SliceStable(stack, func(i, j int) bool {
+		return stack[i].CreatedAt.After(stack[j].CreatedAt)
+	})
+	return stack
+}
+
+func joinIDs(prompts []*models.Prompt) string {
+	ids := make([]string, len(prompts))
+	for i, p := range prompts {
+		ids[i] = p.ID
+	}
+	return strings.Join(ids, ", ")
+}
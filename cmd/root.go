@@ -1,10 +1,18 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/config"
+	"github.com/sunny/pmt/internal/ui"
+)
+
+var (
+	noInteractive bool
+	profile       string
 )
 
 var rootCmd = &cobra.Command{
@@ -13,18 +21,63 @@ var rootCmd = &cobra.Command{
 	Long: `pmt is a CLI tool for managing AI prompt snippets.
 Save, organize, and quickly apply your commonly used prompts for GitHub Copilot and other AI assistants.
 
-Similar to git stash, but for your AI prompts.`,
+Similar to git stash, but for your AI prompts.
+
+When stdin or stdout is not a terminal (scripts, CI, editor panes), selectors
+fall back to a numbered list read from stdin, and fail with the matching IDs
+if there is nothing to read. --no-interactive (or PMT_NO_INTERACTIVE=1) forces
+that mode.
+
+Settings are read from ~/.pmt/config.yaml (see 'pmt config'). From highest to
+lowest precedence, a setting comes from a command line flag, its PMT_*
+environment variable, the repository's .pmt.yaml, the user config file, or
+its default.`,
 	Version: "1.0.0",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if noInteractive {
+			ui.SetNonInteractive(true)
+		}
+		if cmd.Flags().Changed("profile") {
+			if err := config.Override("profile", profile); err != nil {
+				return err
+			}
+		}
+
+		// Display settings; an invalid config file is reported by the
+		// commands that need it, so 'pmt config' can still fix it
+		if cfg, err := config.Load(); err == nil {
+			ui.SetColor(cfg.UseColor())
+			ui.SetPageSize(cfg.SelectorPageSize())
+			ui.SetDateFormat(cfg.DateLayout())
+		}
+		return nil
+	},
+}
+
+// exitError carries the exit code of a child process that pmt should exit with
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
 }
 
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
 func init() {
-	// Global flags can be added here if needed
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Use the prompt store of a profile (overrides the profile setting)")
+	rootCmd.PersistentFlags().BoolVar(&noInteractive, "no-interactive", false, "Never use interactive selectors; read choices from stdin instead")
 }
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/config"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/render"
+	"github.com/sunny/pmt/internal/storage"
+)
+
+var (
+	runRunner    string
+	runSave      bool
+	runVariant   string
+	runVars      map[string]string
+	runFormat    string
+	runModel     string
+	runMaxTokens int
+	runMaxBytes  int
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <id>",
+	Short: "Render a prompt and pipe it into a local command",
+	Long: `Render a prompt and pipe it into a configured command, such as 'llm',
+'ollama run' or any script that reads the prompt from stdin.
+
+The command's stdout and stderr are streamed to the terminal, and pmt exits
+with the command's exit code. Runners are configured in ~/.pmt/config.yaml,
+either as a default, per prompt type, or as named profiles:
+
+  runner: llm
+  runners:
+    llm: llm -m gpt-4o
+    local: ollama run llama3
+  type_runners:
+    review: local
+
+--runner overrides the configuration with a profile name or a literal command.
+The command also receives PMT_PROMPT_ID, PMT_PROMPT_NAME and PMT_PROMPT_TYPE
+in its environment. Use --save to store the response as an annotation on the prompt.`,
+	Example: `  pmt run a7f
+  pmt run a7f --runner local
+  pmt run a7f --runner "ollama run llama3" --save
+  pmt run a7f --as openai-json --runner ./scripts/call-api.sh`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runRun,
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().StringVarP(&runRunner, "runner", "r", "", "Runner profile or command (overrides config)")
+	runCmd.Flags().BoolVarP(&runSave, "save", "s", false, "Save the command's output as an annotation on the prompt")
+	runCmd.Flags().StringVar(&runVariant, "variant", "", "Variant of the prompt to run")
+	runCmd.Flags().StringToStringVar(&runVars, "var", nil, "Set a template variable (name=value)")
+	runCmd.Flags().StringVar(&runFormat, "as", render.FormatPlain, "Input format: plain, openai-json, anthropic-json")
+	runCmd.Flags().StringVar(&runModel, "model", "", "Model name for JSON request bodies")
+	runCmd.Flags().IntVar(&runMaxTokens, "max-tokens", render.DefaultMaxTokens, "max_tokens for anthropic-json")
+	runCmd.Flags().IntVar(&runMaxBytes, "max-bytes", render.DefaultMaxBytes, "Total byte budget for {{file:...}} includes")
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	prompt, err := findPrompt(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	runner, err := cfg.RunnerFor(prompt.Type, runRunner)
+	if err != nil {
+		return err
+	}
+
+	messages, err := renderMessages(store, prompt, renderOptions{
+		MaxBytes: runMaxBytes,
+		Variant:  runVariant,
+		Vars:     runVars,
+	})
+	if err != nil {
+		return err
+	}
+	input, err := render.FormatChat(messages, runFormat, render.ChatOptions{
+		Model:     runModel,
+		MaxTokens: runMaxTokens,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Stream the output, keeping a copy when it should be saved
+	var output bytes.Buffer
+	stdout := io.Writer(os.Stdout)
+	if runSave {
+		stdout = io.MultiWriter(os.Stdout, &output)
+	}
+
+	c := shellCommand(runner)
+	c.Stdin = strings.NewReader(input)
+	c.Stdout = stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(),
+		"PMT_PROMPT_ID="+prompt.ID,
+		"PMT_PROMPT_NAME="+prompt.Name,
+		"PMT_PROMPT_TYPE="+prompt.Type,
+	)
+
+	runErr := c.Run()
+	if runErr != nil {
+		var execErr *exec.ExitError
+		if !errors.As(runErr, &execErr) {
+			return fmt.Errorf("failed to run %q: %w", runner, runErr)
+		}
+		return &exitError{
+			code: execErr.ExitCode(),
+			err:  fmt.Errorf("runner %q exited with code %d", runner, execErr.ExitCode()),
+		}
+	}
+
+	if runSave {
+		err := store.Update(prompt.ID, func(p *models.Prompt) {
+			p.Annotations = append(p.Annotations, models.Annotation{
+				Runner:    runner,
+				Output:    strings.TrimSpace(output.String()),
+				CreatedAt: time.Now(),
+			})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save response: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Saved response as annotation on %s\n", prompt.ID)
+	}
+
+	return nil
+}
+
+// shellCommand builds a command that runs a command line through the platform shell
+func shellCommand(commandLine string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", commandLine)
+	}
+	return exec.Command("sh", "-c", commandLine)
+}
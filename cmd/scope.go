@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sunny/pmt/internal/config"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/utils"
+)
+
+// currentScope returns the project that apply, pop and list are limited to,
+// or "" when they show every project: with --all, or when the 'scope'
+// setting is "all".
+func currentScope(all bool) (string, error) {
+	if all {
+		return "", nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	allProjects, err := cfg.AllProjects()
+	if err != nil {
+		return "", err
+	}
+	if allProjects {
+		return "", nil
+	}
+	return utils.DetectGitProject(), nil
+}
+
+// inferContext returns the context of the current directory within its git
+// repository, mapped by the rules in the repository's .pmt.yaml; at the
+// toplevel it is the default context set there, and outside a repository ""
+func inferContext() (string, error) {
+	dir, err := utils.GitPrefix()
+	if err != nil {
+		return "", nil
+	}
+
+	repo, err := config.CurrentRepo()
+	if err != nil {
+		return "", err
+	}
+	return models.NormalizeContext(repo.ContextFor(dir)), nil
+}
+
+// currentProject identifies the current git project, with the display name
+// set in the repository's .pmt.yaml if there is one
+func currentProject() utils.Project {
+	project := utils.DetectProject()
+	if repo, err := config.CurrentRepo(); err == nil && repo.Name != "" {
+		project.Name = repo.Name
+	}
+	return project
+}
+
+// preferContext moves the prompts in a context or its sub-contexts to the
+// front, keeping the order within both groups
+func preferContext(prompts []models.Prompt, context string) []models.Prompt {
+	if context == "" {
+		return prompts
+	}
+
+	sorted := make([]models.Prompt, 0, len(prompts))
+	var rest []models.Prompt
+	for _, p := range prompts {
+		if p.MatchesContextPrefix(context) {
+			sorted = append(sorted, p)
+		} else {
+			rest = append(rest, p)
+		}
+	}
+	return append(sorted, rest...)
+}
+
+// scopeName returns the display name of a project scope for messages
+func scopeName(project string) string {
+	if current := currentProject(); current.ID == project {
+		return current.Name
+	}
+	return project
+}
+
+// currentBranch returns the branch that apply, pop and peek are limited to: the
+// given --branch, or the checked out branch while they are limited to the
+// current project; "" with --any-branch
+func currentBranch(project, branch string, anyBranch bool) string {
+	if anyBranch {
+		return ""
+	}
+	if branch != "" || project == "" {
+		return branch
+	}
+	return utils.GitBranch()
+}
+
+// noPromptsError explains an empty selection, pointing at --any-branch and
+// --all when the prompts were limited to the current branch or project
+func noPromptsError(project, branch string) error {
+	if branch != "" {
+		return fmt.Errorf("no prompts available in project %s on branch %s. Use --any-branch to include other branches, --all to include other projects, or 'pmt push' to add prompts", scopeName(project), branch)
+	}
+	if project != "" {
+		return fmt.Errorf("no prompts available in project %s. Use --all to include other projects, or 'pmt push' to add prompts", scopeName(project))
+	}
+	return fmt.Errorf("no prompts available. Use 'pmt push' to add prompts")
+}
+
+// scopeHint adds a pointer to --any-branch or --all when a reference could
+// not be resolved within the current branch or project
+func scopeHint(err error, project, branch string, args []string) error {
+	if err == nil || project == "" || len(args) == 0 {
+		return err
+	}
+	if branch != "" {
+		return fmt.Errorf("%w in project %s on branch %s (use --any-branch or --all to search more widely)", err, scopeName(project), branch)
+	}
+	return fmt.Errorf("%w in project %s (use --all to search every project)", err, scopeName(project))
+}
+
+// legacyHint points at 'pmt project relink' when some prompts are still keyed
+// by the current folder name and so are hidden from the project scope
+func legacyHint(store storage.Store) string {
+	project := utils.DetectProject()
+	if project.ID == project.Name {
+		return ""
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return ""
+	}
+
+	count := 0
+	for i := range promptStore.Prompts {
+		if isLegacyProject(&promptStore.Prompts[i], project.Name) {
+			count++
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+
+	verb := "are"
+	if count == 1 {
+		verb = "is"
+	}
+	return fmt.Sprintf("💡 %d prompt%s saved under the folder name '%s' %s hidden; run 'pmt project relink' to include them",
+		count, pluralize(count), project.Name, verb)
+}
@@ -2,10 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/render"
 	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/ui"
+)
+
+var (
+	showExpanded bool
+	showVariant  string
 )
 
 var showCmd = &cobra.Command{
@@ -13,15 +22,20 @@ var showCmd = &cobra.Command{
 	Short: "Show detailed information about a prompt",
 	Long: `Display the complete details of a specific prompt.
 
-You can use the full ID or just a prefix (e.g., 'a7f' instead of 'a7f3c2b').`,
+You can use the full ID or just a prefix (e.g., 'a7f' instead of 'a7f3c2b').
+Use --expanded to show the content with all {{> partial}} and {{file:...}}
+placeholders resolved, exactly as 'pmt apply' would copy it.`,
 	Example: `  pmt show a7f3c2b
-  pmt show a7f`,
+  pmt show a7f
+  pmt show a7f --expanded`,
 	Args: cobra.ExactArgs(1),
 	RunE: runShow,
 }
 
 func init() {
 	rootCmd.AddCommand(showCmd)
+	showCmd.Flags().BoolVarP(&showExpanded, "expanded", "e", false, "Show content with includes and file placeholders resolved")
+	showCmd.Flags().StringVar(&showVariant, "variant", "", "Show a variant instead of the default content")
 }
 
 func runShow(cmd *cobra.Command, args []string) error {
@@ -38,7 +52,45 @@ func runShow(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Display the prompt details
+	// The variant may come from a base, whose variant content the prompt
+	// extends with its own default content
+	content := prompt.Text()
+	var source *models.Prompt
+	if showVariant != "" {
+		renderer, err := newRenderer(store, renderOptions{})
+		if err != nil {
+			return err
+		}
+		source, err = renderer.VariantSource(prompt, showVariant)
+		if err != nil {
+			return err
+		}
+		if source == nil {
+			return fmt.Errorf("prompt %s has no variant %q", prompt.ID, showVariant)
+		}
+		if source == prompt {
+			content = prompt.Variants[showVariant]
+		}
+	}
+	if showExpanded {
+		content, err = renderContent(store, prompt, renderOptions{
+			MaxBytes: render.DefaultMaxBytes,
+			Variant:  showVariant,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	printPromptDetails(prompt, content)
+	if source != nil && source != prompt && !showExpanded {
+		fmt.Printf("💡 Variant %s is defined by base %s; use --expanded to see it applied\n", showVariant, source.ID)
+	}
+	return nil
+}
+
+// printPromptDetails prints a prompt's metadata followed by the given content
+func printPromptDetails(prompt *models.Prompt, content string) {
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	fmt.Printf("ID:        %s\n", prompt.ID)
 
@@ -47,23 +99,68 @@ func runShow(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("Type:      %s\n", prompt.Type)
-	fmt.Printf("Project:   %s\n", prompt.Project)
+	project := prompt.DisplayProject()
+	if prompt.ProjectName != "" && prompt.ProjectName != prompt.Project {
+		project += " (" + prompt.Project + ")"
+	}
+	if prompt.Global {
+		project += " [global]"
+	}
+	fmt.Printf("Project:   %s\n", project)
 
 	if prompt.Context != "" {
 		fmt.Printf("Context:   %s\n", prompt.Context)
 	}
 
+	if prompt.Branch != "" {
+		branch := prompt.Branch
+		if prompt.Commit != "" {
+			branch += " @ " + prompt.Commit[:min(7, len(prompt.Commit))]
+		}
+		if prompt.Archived {
+			branch += " [archived]"
+		}
+		fmt.Printf("Branch:    %s\n", branch)
+	} else if prompt.Archived {
+		fmt.Println("Archived:  yes")
+	}
+
+	if prompt.Extends != "" {
+		fmt.Printf("Extends:   %s\n", prompt.Extends)
+	}
+
+	if len(prompt.Vars) > 0 {
+		vars := make([]string, 0, len(prompt.Vars))
+		for k, v := range prompt.Vars {
+			vars = append(vars, k+"="+v)
+		}
+		sort.Strings(vars)
+		fmt.Printf("Vars:      %s\n", strings.Join(vars, ", "))
+	}
+
+	if prompt.IsChat() {
+		fmt.Printf("Messages:  %d\n", len(prompt.Messages))
+	}
+
+	if len(prompt.Variants) > 0 {
+		fmt.Printf("Variants:  %s\n", strings.Join(prompt.VariantNames(), ", "))
+	}
+
 	if len(prompt.Tags) > 0 {
 		fmt.Printf("Tags:      %s\n", strings.Join(prompt.Tags, ", "))
 	} else {
 		fmt.Println("Tags:      (none)")
 	}
 
-	fmt.Printf("Created:   %s\n", prompt.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Created:   %s\n", ui.FormatDate(prompt.CreatedAt))
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	fmt.Println("\nContent:")
-	fmt.Println(prompt.Content)
+	fmt.Println(content)
 	fmt.Println()
 
-	return nil
+	for _, a := range prompt.Annotations {
+		fmt.Printf("── Response from %s (%s)\n", a.Runner, ui.FormatDate(a.CreatedAt))
+		fmt.Println(a.Output)
+		fmt.Println()
+	}
 }
@@ -0,0 +1,521 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/config"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+)
+
+var (
+	tagNames  []string
+	tagInto   string
+	tagAll    bool
+	tagDryRun bool
+	tagUnset  bool
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage tags",
+	Long: `Manage the tags of prompts.
+
+Tags are normalized when they are written: surrounding whitespace is removed,
+duplicates are dropped and, unless the tag_case setting is "preserve", they
+are lowercased. They are always compared case-insensitively.
+
+Tags can be hierarchical, like contexts: lang/go or area/db/redis. Filtering
+on a tag also matches its sub-tags, so 'pmt list --tag lang' finds prompts
+tagged lang/go and lang/rust. Renaming, merging and removing a tag applies to
+its sub-tags as well.
+
+Synonyms map other vocabulary onto one tag, e.g. pg -> postgres. They are
+resolved when tags are written and when prompts are filtered by tag, so
+'pmt list --tag pg' finds prompts tagged postgres. Set them with
+'pmt tag alias', or share them in tag_synonyms in the repository's .pmt.yaml.`,
+	Example: `  pmt tag list
+  pmt tag rename postgres postgresql
+  pmt tag merge db database sql --into database
+  pmt tag add a7f b2c --tag security
+  pmt tag rm a7f --tag wip
+  pmt tag alias pg postgres`,
+}
+
+var tagListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List tags with their prompt counts",
+	Long:    `List every tag used by prompts, most used first, with its prompt count.`,
+	Example: `  pmt tag list
+  pmt tag ls`,
+	Args: cobra.NoArgs,
+	RunE: runTagList,
+}
+
+var tagRenameCmd = &cobra.Command{
+	Use:     "rename <old> <new>",
+	Aliases: []string{"mv"},
+	Short:   "Rename a tag on every prompt",
+	Long: `Rename a tag and its sub-tags on every prompt, trashed prompt and context
+default. Prompts that already have the new tag keep it once.`,
+	Example: `  pmt tag rename postgres postgresql
+  pmt tag rename lang language          # lang/go becomes language/go
+  pmt tag mv WIP draft --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: runTagRename,
+}
+
+var tagMergeCmd = &cobra.Command{
+	Use:   "merge <tag>... --into <tag>",
+	Short: "Replace several tags with one",
+	Long: `Replace each of the given tags with the --into tag on every prompt, trashed
+prompt and context default. Sub-tags move along, e.g. merging db into
+database turns db/redis into database/redis.`,
+	Example: `  pmt tag merge db sql --into database
+  pmt tag merge js javascript ecmascript --into javascript --dry-run`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runTagMerge,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <id>... --tag <tag>",
+	Short: "Add tags to prompts",
+	Long:  `Add one or more tags to the given prompts, referred to by ID, ID prefix or name.`,
+	Example: `  pmt tag add a7f --tag security
+  pmt tag add a7f review --tag go,backend`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runTagAdd,
+}
+
+var tagRemoveCmd = &cobra.Command{
+	Use:     "rm [id]... --tag <tag>",
+	Aliases: []string{"remove"},
+	Short:   "Remove tags from prompts",
+	Long: `Remove one or more tags and their sub-tags from the given prompts, or from
+every prompt, trashed prompt and context default with --all.`,
+	Example: `  pmt tag rm a7f --tag wip
+  pmt tag rm --tag obsolete --all --dry-run`,
+	RunE: runTagRemove,
+}
+
+var tagAliasCmd = &cobra.Command{
+	Use:   "alias [<synonym> <tag>]",
+	Short: "List or set tag synonyms",
+	Long: `List the tag synonyms in effect, or make a synonym resolve to a tag. Synonyms
+are kept in tag_synonyms in ~/.pmt/config.yaml; those in the repository's
+.pmt.yaml are shared with everyone working in it and take precedence.
+
+A synonym also covers its sub-tags: with pg -> db/postgres, pg/replication is
+stored as db/postgres/replication. Prompts already tagged with the synonym
+keep it until you run 'pmt tag merge <synonym> --into <tag>'.`,
+	Example: `  pmt tag alias
+  pmt tag alias pg postgres
+  pmt tag alias golang lang/go
+  pmt tag alias pg --unset`,
+	Args: cobra.RangeArgs(0, 2),
+	RunE: runTagAlias,
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagListCmd)
+	tagCmd.AddCommand(tagRenameCmd)
+	tagCmd.AddCommand(tagMergeCmd)
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagAliasCmd)
+
+	tagMergeCmd.Flags().StringVar(&tagInto, "into", "", "Tag that replaces the merged tags")
+	tagMergeCmd.MarkFlagRequired("into")
+	for _, c := range []*cobra.Command{tagAddCmd, tagRemoveCmd} {
+		c.Flags().StringSliceVarP(&tagNames, "tag", "g", nil, "Tags (comma-separated)")
+		c.MarkFlagRequired("tag")
+	}
+	tagAliasCmd.Flags().BoolVar(&tagUnset, "unset", false, "Remove the synonym")
+	tagRemoveCmd.Flags().BoolVarP(&tagAll, "all", "a", false, "Remove the tags from every prompt")
+	for _, c := range []*cobra.Command{tagRenameCmd, tagMergeCmd, tagRemoveCmd} {
+		c.Flags().BoolVar(&tagDryRun, "dry-run", false, "Show what would change without changing anything")
+	}
+}
+
+// tagPolicy returns how tags are normalized, from the tag_case setting
+func tagPolicy() (models.TagPolicy, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return models.TagPolicy{}, err
+	}
+	return cfg.TagPolicy(), nil
+}
+
+func runTagList(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	// Count case-insensitively, showing the first spelling seen
+	counts := make(map[string]int)
+	spelling := make(map[string]string)
+	for _, p := range promptStore.Prompts {
+		for _, tag := range p.Tags {
+			key := strings.ToLower(strings.TrimSpace(tag))
+			if key == "" {
+				continue
+			}
+			if _, ok := spelling[key]; !ok {
+				spelling[key] = strings.TrimSpace(tag)
+			}
+			counts[key]++
+		}
+	}
+
+	if len(counts) == 0 {
+		fmt.Println("No tags found. Add some with 'pmt push --tags' or 'pmt tag add'.")
+		return nil
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Printf("%-30s %s\n", "Tag", "Prompts")
+	fmt.Println(strings.Repeat("-", 38))
+	for _, key := range keys {
+		fmt.Printf("%-30s %d\n", spelling[key], counts[key])
+	}
+	fmt.Printf("\nTotal: %d tag%s\n", len(keys), pluralize(len(keys)))
+	return nil
+}
+
+func runTagRename(cmd *cobra.Command, args []string) error {
+	return replaceTags(args[:1], args[1])
+}
+
+func runTagMerge(cmd *cobra.Command, args []string) error {
+	return replaceTags(args, tagInto)
+}
+
+// replaceTags replaces the tags from with the tag into on every prompt,
+// trashed prompt and context record
+func replaceTags(from []string, into string) error {
+	policy, err := tagPolicy()
+	if err != nil {
+		return err
+	}
+	into = policy.NormalizeTag(into)
+	if into == "" {
+		return fmt.Errorf("tag cannot be empty")
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	affected := taggedPrompts(promptStore.Prompts, from)
+	if len(affected) == 0 {
+		return fmt.Errorf("no prompts tagged %s", strings.Join(from, ", "))
+	}
+	if tagDryRun {
+		fmt.Printf("Would replace %s with %s on %d prompt%s:\n", strings.Join(from, ", "), into, len(affected), pluralize(len(affected)))
+		printAffected(affected)
+		return nil
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		rewriteTags(s, policy, func(tags []string) []string {
+			var replaced []string
+			for _, tag := range tags {
+				for _, source := range from {
+					if moved, ok := models.MoveTag(tag, source, into); ok {
+						tag = moved
+						break
+					}
+				}
+				replaced = append(replaced, tag)
+			}
+			return replaced
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update tags: %w", err)
+	}
+
+	fmt.Printf("✓ Replaced %s with %s\n", strings.Join(from, ", "), into)
+	fmt.Printf("  Updated %d prompt%s\n", len(affected), pluralize(len(affected)))
+	return nil
+}
+
+func runTagAdd(cmd *cobra.Command, args []string) error {
+	policy, err := tagPolicy()
+	if err != nil {
+		return err
+	}
+	tags := policy.NormalizeTags(tagNames)
+	if len(tags) == 0 {
+		return fmt.Errorf("tag cannot be empty")
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	ids, err := findPromptIDs(store, args)
+	if err != nil {
+		return err
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		for i := range s.Prompts {
+			if ids[s.Prompts[i].ID] {
+				s.Prompts[i].Tags = policy.NormalizeTags(append(s.Prompts[i].Tags, tags...))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update tags: %w", err)
+	}
+
+	fmt.Printf("✓ Tagged %d prompt%s with %s\n", len(ids), pluralize(len(ids)), strings.Join(tags, ", "))
+	return nil
+}
+
+func runTagRemove(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && !tagAll {
+		return fmt.Errorf("give the prompts to remove the tags from, or --all for every prompt")
+	}
+	if len(args) > 0 && tagAll {
+		return fmt.Errorf("--all cannot be combined with prompt IDs")
+	}
+
+	policy, err := tagPolicy()
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	// Without --all, only the given prompts are changed
+	var ids map[string]bool
+	if !tagAll {
+		if ids, err = findPromptIDs(store, args); err != nil {
+			return err
+		}
+	}
+
+	// Tags given by a synonym are removed too
+	names := append([]string(nil), tagNames...)
+	for _, name := range tagNames {
+		names = append(names, policy.NormalizeTag(name))
+	}
+
+	var affected []*models.Prompt
+	for _, p := range taggedPrompts(promptStore.Prompts, names) {
+		if tagAll || ids[p.ID] {
+			affected = append(affected, p)
+		}
+	}
+	if len(affected) == 0 {
+		return fmt.Errorf("no prompts tagged %s", strings.Join(tagNames, ", "))
+	}
+	if tagDryRun {
+		fmt.Printf("Would remove %s from %d prompt%s:\n", strings.Join(tagNames, ", "), len(affected), pluralize(len(affected)))
+		printAffected(affected)
+		return nil
+	}
+
+	untag := func(tags []string) []string {
+		var kept []string
+		for _, tag := range tags {
+			if !inAnyTag(tag, names) {
+				kept = append(kept, tag)
+			}
+		}
+		return kept
+	}
+	err = store.Modify(func(s *models.PromptStore) error {
+		if tagAll {
+			rewriteTags(s, policy, untag)
+			return nil
+		}
+		for i := range s.Prompts {
+			if ids[s.Prompts[i].ID] {
+				s.Prompts[i].Tags = rewriteTagList(s.Prompts[i].Tags, policy, untag)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update tags: %w", err)
+	}
+
+	fmt.Printf("✓ Removed %s from %d prompt%s\n", strings.Join(tagNames, ", "), len(affected), pluralize(len(affected)))
+	return nil
+}
+
+func runTagAlias(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		policy, err := tagPolicy()
+		if err != nil {
+			return err
+		}
+		if len(policy.Synonyms) == 0 {
+			fmt.Println("No tag synonyms. Add one with 'pmt tag alias <synonym> <tag>'.")
+			return nil
+		}
+
+		synonyms := make([]string, 0, len(policy.Synonyms))
+		for synonym := range policy.Synonyms {
+			synonyms = append(synonyms, synonym)
+		}
+		sort.Strings(synonyms)
+		for _, synonym := range synonyms {
+			fmt.Printf("%-20s -> %s\n", synonym, policy.Synonyms[synonym])
+		}
+		return nil
+	}
+
+	cfg, err := config.LoadUser()
+	if err != nil {
+		return fmt.Errorf("%w (run 'pmt config edit' to fix it)", err)
+	}
+	synonym := models.TagPolicy{Lowercase: cfg.TagPolicy().Lowercase}.NormalizeTag(args[0])
+	if synonym == "" {
+		return fmt.Errorf("synonym cannot be empty")
+	}
+
+	if tagUnset {
+		if len(args) != 1 {
+			return fmt.Errorf("--unset takes only the synonym")
+		}
+		if _, ok := cfg.TagSynonyms[synonym]; !ok {
+			return fmt.Errorf("no synonym %s in %s", synonym, "~/.pmt/config.yaml")
+		}
+		delete(cfg.TagSynonyms, synonym)
+		if err := config.SaveUser(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Removed tag synonym: %s\n", synonym)
+		return nil
+	}
+
+	if len(args) != 2 {
+		return fmt.Errorf("give the synonym and the tag it stands for, e.g. 'pmt tag alias pg postgres'")
+	}
+	tag := models.TagPolicy{Lowercase: cfg.TagPolicy().Lowercase}.NormalizeTag(args[1])
+	if tag == "" {
+		return fmt.Errorf("tag cannot be empty")
+	}
+	if models.InTag(tag, synonym) {
+		return fmt.Errorf("tag %s cannot be the synonym %s or one of its sub-tags", tag, synonym)
+	}
+
+	if cfg.TagSynonyms == nil {
+		cfg.TagSynonyms = make(map[string]string)
+	}
+	cfg.TagSynonyms[synonym] = tag
+	if err := config.SaveUser(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Tag %s now stands for %s\n", synonym, tag)
+	fmt.Printf("💡 Run 'pmt tag merge %s --into %s' to update prompts already tagged %s\n", synonym, tag, synonym)
+	return nil
+}
+
+// findPromptIDs resolves prompt references to the set of their IDs
+func findPromptIDs(store storage.Store, refs []string) (map[string]bool, error) {
+	ids := make(map[string]bool)
+	for _, ref := range refs {
+		prompt, err := findPrompt(store, ref)
+		if err != nil {
+			return nil, err
+		}
+		ids[prompt.ID] = true
+	}
+	return ids, nil
+}
+
+// inAnyTag reports whether tag is one of the given tags or their sub-tags
+func inAnyTag(tag string, tags []string) bool {
+	for _, t := range tags {
+		if models.InTag(tag, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// taggedPrompts returns the prompts with any of the given tags or their sub-tags
+func taggedPrompts(prompts []models.Prompt, tags []string) []*models.Prompt {
+	var matches []*models.Prompt
+	for i := range prompts {
+		for _, tag := range tags {
+			if prompts[i].HasTag(tag) {
+				matches = append(matches, &prompts[i])
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// rewriteTags applies fn to the tags of every prompt, trashed prompt and
+// context record. Only the tag lists fn changes are normalized; the others
+// are left exactly as they are.
+func rewriteTags(s *models.PromptStore, policy models.TagPolicy, fn func([]string) []string) {
+	for _, prompts := range [][]models.Prompt{s.Prompts, s.Trash} {
+		for i := range prompts {
+			prompts[i].Tags = rewriteTagList(prompts[i].Tags, policy, fn)
+		}
+	}
+	for i := range s.Contexts {
+		s.Contexts[i].Tags = rewriteTagList(s.Contexts[i].Tags, policy, fn)
+	}
+}
+
+// rewriteTagList applies fn to tags, normalizing the result only if fn
+// changed them
+func rewriteTagList(tags []string, policy models.TagPolicy, fn func([]string) []string) []string {
+	if len(tags) == 0 {
+		return tags
+	}
+	rewritten := fn(tags)
+	if slices.Equal(rewritten, tags) {
+		return tags
+	}
+	return policy.NormalizeTags(rewritten)
+}
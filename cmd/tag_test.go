@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+func TestRewriteTagsLeavesUntouchedLists(t *testing.T) {
+	policy := models.TagPolicy{Lowercase: true, Synonyms: map[string]string{"pg": "postgres"}}
+	s := &models.PromptStore{
+		Prompts: []models.Prompt{
+			{ID: "a", Tags: []string{"Old", "Extra "}},
+			{ID: "b", Tags: []string{"Legacy Spelling ", "pg"}},
+			{ID: "c"},
+		},
+		Trash:    []models.Prompt{{ID: "d", Tags: []string{"PG", "old/Sub"}}},
+		Contexts: []models.Context{{Path: "api", Tags: []string{"Keep Me"}}},
+	}
+
+	rename := func(tags []string) []string {
+		var renamed []string
+		for _, tag := range tags {
+			if moved, ok := models.MoveTag(tag, "old", "new"); ok {
+				tag = moved
+			}
+			renamed = append(renamed, tag)
+		}
+		return renamed
+	}
+	rewriteTags(s, policy, rename)
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"renamed prompt is normalized", s.Prompts[0].Tags, []string{"new", "extra"}},
+		{"untouched prompt keeps its spelling", s.Prompts[1].Tags, []string{"Legacy Spelling ", "pg"}},
+		{"prompt without tags", s.Prompts[2].Tags, nil},
+		{"renamed trashed prompt", s.Trash[0].Tags, []string{"postgres", "new/sub"}},
+		{"untouched context record", s.Contexts[0].Tags, []string{"Keep Me"}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: tags = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
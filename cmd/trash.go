@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/ui"
+)
+
+var (
+	trashRestoreProject string
+	trashEmptyForce     bool
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage deleted prompts",
+	Long: `Manage prompts in the trash.
+
+Deleting a project moves its prompts to the trash, from where they can be
+restored until the trash is emptied.`,
+	Example: `  pmt trash list
+  pmt trash restore a7f
+  pmt trash restore --project scratch
+  pmt trash empty`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List prompts in the trash",
+	Args:    cobra.NoArgs,
+	RunE:    runTrashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore [id...]",
+	Short: "Restore prompts from the trash",
+	Long: `Move prompts from the trash back into the store, by ID or ID prefix,
+or all prompts of a deleted project with --project.`,
+	Example: `  pmt trash restore a7f 9d4
+  pmt trash restore --project scratch`,
+	RunE: runTrashRestore,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete the prompts in the trash",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashEmpty,
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+	trashRestoreCmd.Flags().StringVarP(&trashRestoreProject, "project", "p", "", "Restore every prompt of this project")
+	trashEmptyCmd.Flags().BoolVarP(&trashEmptyForce, "force", "f", false, "Empty the trash without confirmation")
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	if len(promptStore.Trash) == 0 {
+		fmt.Println("The trash is empty.")
+		return nil
+	}
+
+	fmt.Printf("%-9s %-20s %-12s %-30s %s\n", "ID", "Name", "Project", "Content", "Deleted")
+	fmt.Println(strings.Repeat("-", 95))
+
+	for i := range promptStore.Trash {
+		p := &promptStore.Trash[i]
+		name := p.Name
+		if name == "" {
+			name = "-"
+		}
+		fmt.Printf("%-9s %-20s %-12s %-30s %s\n",
+			p.ID,
+			truncateString(name, 20),
+			truncateString(p.DisplayProject(), 12),
+			truncateString(strings.Join(strings.Fields(p.Text()), " "), 30),
+			ui.FormatDate(p.DeletedAt),
+		)
+	}
+
+	fmt.Printf("\nTotal: %d prompt(s)\n", len(promptStore.Trash))
+	return nil
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 && trashRestoreProject == "" {
+		return fmt.Errorf("pass the IDs of the prompts to restore, or --project")
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	ids := args
+	if trashRestoreProject != "" {
+		promptStore, err := store.LoadAll()
+		if err != nil {
+			return fmt.Errorf("failed to load prompts: %w", err)
+		}
+
+		project, err := resolveProject(promptStore.Trash, trashRestoreProject)
+		if err != nil {
+			return err
+		}
+		for _, p := range projectPrompts(promptStore.Trash, project.ID) {
+			ids = append(ids, p.ID)
+		}
+	}
+
+	if err := store.Restore(ids); err != nil {
+		return fmt.Errorf("failed to restore prompts: %w", err)
+	}
+
+	fmt.Printf("✓ Restored %d prompt%s\n", len(ids), pluralize(len(ids)))
+	return nil
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	if !trashEmptyForce {
+		confirmed, err := ui.Confirm("Permanently delete all prompts in the trash?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	count, err := store.EmptyTrash()
+	if err != nil {
+		return fmt.Errorf("failed to empty trash: %w", err)
+	}
+
+	fmt.Printf("✓ Permanently deleted %d prompt%s\n", count, pluralize(count))
+	return nil
+}
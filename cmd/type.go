@@ -0,0 +1,481 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/config"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+)
+
+var (
+	typeDescription  string
+	typeColor        string
+	typeTemplate     string
+	typeEditTemplate bool
+	typeInto         string
+	typeDryRun       bool
+)
+
+var typeCmd = &cobra.Command{
+	Use:   "type",
+	Short: "Manage prompt types",
+	Long: `Manage the registry of prompt types.
+
+Every prompt has a type, such as bugfix or feature. The registry starts out
+with bugfix, feature, refactor, test and general, and can be extended with
+your own types, e.g. review, docs or migration. Each type can have a
+description, a color used in selectors, and a template that the editor opens
+with when pushing a prompt of that type.
+
+The registry is kept in the user's store, also inside a repository with a
+store of its own (prompt_dir in .pmt.yaml); a repository limits the types it
+allows with types in its .pmt.yaml instead.`,
+	Example: `  pmt type list
+  pmt type add review -d "Code review checklists" --color cyan
+  pmt type describe review --template "Review this diff for:"
+  pmt type rename review code-review
+  pmt type remove docs --into general`,
+}
+
+var typeListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List types with their prompt counts",
+	Long: `List the registered types with their prompt counts, colors and descriptions.
+Types used by prompts that are not registered, e.g. after editing the store
+by hand, are listed as well.`,
+	Example: `  pmt type list
+  pmt type ls`,
+	Args: cobra.NoArgs,
+	RunE: runTypeList,
+}
+
+var typeAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Register a new type",
+	Long: `Register a new type. Names are lowercase and consist of letters, digits,
+- and _.
+
+With --template (or --edit-template to write it in the editor), pushing a
+prompt of this type without content opens the editor on the template.`,
+	Example: `  pmt type add review -d "Code review checklists" --color cyan
+  pmt type add migration --edit-template`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTypeAdd,
+}
+
+var typeDescribeCmd = &cobra.Command{
+	Use:   "describe <name>",
+	Short: "Show or change a type's description, color and template",
+	Long: `Show a type's description, color, template and prompt count, or change them
+with the flags below. An empty value removes the description, color or
+template.`,
+	Example: `  pmt type describe review
+  pmt type describe review --color magenta
+  pmt type describe review --edit-template`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTypeDescribe,
+}
+
+var typeRenameCmd = &cobra.Command{
+	Use:     "rename <old> <new>",
+	Aliases: []string{"mv"},
+	Short:   "Rename a type and the prompts that have it",
+	Long: `Rename a type in the registry and rewrite every prompt, trashed prompt and
+context default that uses it. To fold a type into another existing one, use
+'pmt type remove --into'.`,
+	Example: `  pmt type rename review code-review
+  pmt type rename docs documentation --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: runTypeRename,
+}
+
+var typeRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a type from the registry",
+	Long: `Remove a type from the registry.
+
+A type that prompts still use is only removed with --into, which moves those
+prompts, trashed prompts and context defaults to another type.`,
+	Example: `  pmt type remove docs
+  pmt type rm docs --into general --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTypeRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(typeCmd)
+	typeCmd.AddCommand(typeListCmd)
+	typeCmd.AddCommand(typeAddCmd)
+	typeCmd.AddCommand(typeDescribeCmd)
+	typeCmd.AddCommand(typeRenameCmd)
+	typeCmd.AddCommand(typeRemoveCmd)
+
+	for _, c := range []*cobra.Command{typeAddCmd, typeDescribeCmd} {
+		c.Flags().StringVarP(&typeDescription, "description", "d", "", "Description of the type")
+		c.Flags().StringVar(&typeColor, "color", "", "Color in selectors: "+strings.Join(models.TypeColors, ", "))
+		c.Flags().StringVar(&typeTemplate, "template", "", "Initial content of the editor when pushing a prompt of this type")
+		c.Flags().BoolVar(&typeEditTemplate, "edit-template", false, "Write the template in the editor")
+	}
+	typeRemoveCmd.Flags().StringVar(&typeInto, "into", "", "Move prompts of the removed type to this type")
+	for _, c := range []*cobra.Command{typeRenameCmd, typeRemoveCmd} {
+		c.Flags().BoolVar(&typeDryRun, "dry-run", false, "Show what would change without changing anything")
+	}
+}
+
+func runTypeList(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	counts := typeCounts(promptStore)
+	fmt.Printf("%-14s %-8s %-9s %-9s %s\n", "Type", "Prompts", "Color", "Template", "Description")
+	fmt.Println(strings.Repeat("-", 70))
+	for _, t := range promptStore.TypeRegistry() {
+		template := "-"
+		if t.Template != "" {
+			template = "yes"
+		}
+		fmt.Printf("%-14s %-8d %-9s %-9s %s\n", t.Name, counts[strings.ToLower(t.Name)], orDash(t.Color), template, t.Description)
+		delete(counts, strings.ToLower(t.Name))
+	}
+
+	// Types found on prompts only
+	var unregistered []string
+	for name := range counts {
+		unregistered = append(unregistered, name)
+	}
+	sort.Strings(unregistered)
+	for _, name := range unregistered {
+		fmt.Printf("%-14s %-8d %-9s %-9s %s\n", name, counts[name], "-", "-", "(not registered)")
+	}
+	if len(unregistered) > 0 {
+		fmt.Println("\n💡 Register a type with 'pmt type add', or move its prompts with 'pmt type rename'")
+	}
+	return nil
+}
+
+func runTypeAdd(cmd *cobra.Command, args []string) error {
+	name, err := models.NormalizeTypeName(args[0])
+	if err != nil {
+		return err
+	}
+	if err := models.ValidateTypeColor(typeColor); err != nil {
+		return err
+	}
+	template, err := typeTemplateFlag("")
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		if s.FindType(name) != nil {
+			return fmt.Errorf("type %s already exists. Use 'pmt type describe' to change it", name)
+		}
+		s.StoreTypes()
+		s.Types = append(s.Types, models.PromptType{
+			Name:        name,
+			Description: strings.TrimSpace(typeDescription),
+			Color:       typeColor,
+			Template:    template,
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Added type: %s\n", name)
+	return nil
+}
+
+func runTypeDescribe(cmd *cobra.Command, args []string) error {
+	if err := models.ValidateTypeColor(typeColor); err != nil {
+		return err
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	t := promptStore.FindType(args[0])
+	if t == nil {
+		return fmt.Errorf("type %s not found. Use 'pmt type add' to register it", args[0])
+	}
+
+	flags := cmd.Flags()
+	if !flags.Changed("description") && !flags.Changed("color") && !flags.Changed("template") && !typeEditTemplate {
+		printTypeDetails(promptStore, t)
+		return nil
+	}
+
+	template, err := typeTemplateFlag(t.Template)
+	if err != nil {
+		return err
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		s.StoreTypes()
+		record := s.FindType(t.Name)
+		if record == nil {
+			return fmt.Errorf("type %s not found", t.Name)
+		}
+		if flags.Changed("description") {
+			record.Description = strings.TrimSpace(typeDescription)
+		}
+		if flags.Changed("color") {
+			record.Color = typeColor
+		}
+		if flags.Changed("template") || typeEditTemplate {
+			record.Template = template
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update type: %w", err)
+	}
+
+	fmt.Printf("✓ Updated type: %s\n", t.Name)
+	return nil
+}
+
+// printTypeDetails prints a type's registry entry and prompt count
+func printTypeDetails(promptStore *models.PromptStore, t *models.PromptType) {
+	fmt.Printf("Type:        %s\n", t.Name)
+	if t.Description != "" {
+		fmt.Printf("Description: %s\n", t.Description)
+	}
+	if t.Color != "" {
+		fmt.Printf("Color:       %s\n", t.Color)
+	}
+	fmt.Printf("Prompts:     %d\n", typeCounts(promptStore)[strings.ToLower(t.Name)])
+	if t.Template != "" {
+		fmt.Printf("\nTemplate:\n%s\n", t.Template)
+	}
+}
+
+func runTypeRename(cmd *cobra.Command, args []string) error {
+	newName, err := models.NormalizeTypeName(args[1])
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	t := promptStore.FindType(args[0])
+	if t == nil {
+		return fmt.Errorf("type %s not found", args[0])
+	}
+	oldName := t.Name
+	if existing := promptStore.FindType(newName); existing != nil {
+		if existing.Name == oldName {
+			return fmt.Errorf("type %s already has that name", oldName)
+		}
+		return fmt.Errorf("type %s already exists. Use 'pmt type remove %s --into %s' to merge them", newName, oldName, newName)
+	}
+
+	affected := typePrompts(promptStore.Prompts, oldName)
+	if typeDryRun {
+		fmt.Printf("Would rename type %s to %s (%d prompt%s):\n", oldName, newName, len(affected), pluralize(len(affected)))
+		printAffected(affected)
+		return nil
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		s.StoreTypes()
+		record := s.FindType(oldName)
+		if record == nil {
+			return fmt.Errorf("type %s not found", oldName)
+		}
+		record.Name = newName
+		retype(s, oldName, newName)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename type: %w", err)
+	}
+
+	fmt.Printf("✓ Renamed type %s to %s\n", oldName, newName)
+	fmt.Printf("  Updated %d prompt%s\n", len(affected), pluralize(len(affected)))
+	typeConfigHint(oldName)
+	return nil
+}
+
+func runTypeRemove(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+
+	t := promptStore.FindType(args[0])
+	if t == nil {
+		return fmt.Errorf("type %s not found", args[0])
+	}
+	name := t.Name
+
+	into := ""
+	if typeInto != "" {
+		target := promptStore.FindType(typeInto)
+		if target == nil {
+			return fmt.Errorf("type %s not found", typeInto)
+		}
+		if target.Name == name {
+			return fmt.Errorf("cannot move type %s into itself", name)
+		}
+		into = target.Name
+	}
+
+	affected := typePrompts(promptStore.Prompts, name)
+	if len(affected) > 0 && into == "" {
+		return fmt.Errorf("type %s is used by %d prompt%s. Use --into to move them to another type", name, len(affected), pluralize(len(affected)))
+	}
+	if len(promptStore.TypeRegistry()) == 1 {
+		return fmt.Errorf("cannot remove %s, the only registered type", name)
+	}
+
+	if typeDryRun {
+		if into != "" {
+			fmt.Printf("Would remove type %s and move %d prompt%s to %s:\n", name, len(affected), pluralize(len(affected)), into)
+			printAffected(affected)
+		} else {
+			fmt.Printf("Would remove type %s\n", name)
+		}
+		return nil
+	}
+
+	err = store.Modify(func(s *models.PromptStore) error {
+		s.StoreTypes()
+		types := s.Types[:0]
+		for _, t := range s.Types {
+			if t.Name != name {
+				types = append(types, t)
+			}
+		}
+		s.Types = types
+		if into != "" {
+			retype(s, name, into)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove type: %w", err)
+	}
+
+	fmt.Printf("✓ Removed type: %s\n", name)
+	if len(affected) > 0 {
+		fmt.Printf("  Moved %d prompt%s to %s\n", len(affected), pluralize(len(affected)), into)
+	}
+	typeConfigHint(name)
+	return nil
+}
+
+// checkType checks that a type, e.g. one to filter prompts by, is registered
+func checkType(store storage.Store, name string) error {
+	if name == "" {
+		return nil
+	}
+	promptStore, err := store.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load prompts: %w", err)
+	}
+	_, err = promptStore.ValidateType(name)
+	return err
+}
+
+// typePrompts returns the prompts of a type
+func typePrompts(prompts []models.Prompt, name string) []*models.Prompt {
+	var matches []*models.Prompt
+	for i := range prompts {
+		if strings.EqualFold(prompts[i].Type, name) {
+			matches = append(matches, &prompts[i])
+		}
+	}
+	return matches
+}
+
+// retype changes the type of all prompts, trashed prompts and context
+// defaults from one type to another
+func retype(s *models.PromptStore, from, to string) {
+	for _, prompts := range [][]models.Prompt{s.Prompts, s.Trash} {
+		for i := range prompts {
+			if strings.EqualFold(prompts[i].Type, from) {
+				prompts[i].Type = to
+			}
+		}
+	}
+	for i := range s.Contexts {
+		if strings.EqualFold(s.Contexts[i].Type, from) {
+			s.Contexts[i].Type = to
+		}
+	}
+}
+
+// typeCounts counts the prompts of each type, by lowercase name
+func typeCounts(s *models.PromptStore) map[string]int {
+	counts := make(map[string]int)
+	for _, p := range s.Prompts {
+		counts[strings.ToLower(p.Type)]++
+	}
+	return counts
+}
+
+// typeTemplateFlag returns the template given with --template, or written in
+// the editor with --edit-template starting from current
+func typeTemplateFlag(current string) (string, error) {
+	if !typeEditTemplate {
+		return strings.TrimSpace(typeTemplate), nil
+	}
+	if typeTemplate != "" {
+		current = typeTemplate
+	}
+	edited, err := editText(current)
+	if err != nil {
+		return "", fmt.Errorf("failed to open editor: %w", err)
+	}
+	return strings.TrimSpace(edited), nil
+}
+
+// typeConfigHint points at settings that still name a renamed or removed type
+func typeConfigHint(name string) {
+	if cfg, err := config.Load(); err == nil && strings.EqualFold(cfg.DefaultType, name) {
+		fmt.Printf("💡 The default_type setting still names %s; change it with 'pmt config set default_type'\n", name)
+	}
+	if repo, err := config.CurrentRepo(); err == nil && containsFold(repo.Types, name) {
+		fmt.Printf("💡 The types in %s still name %s\n", config.RepoFile, name)
+	}
+}
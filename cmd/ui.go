@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/render"
+	"github.com/sunny/pmt/internal/storage"
+	"github.com/sunny/pmt/internal/ui"
+)
+
+var (
+	uiTo   string
+	uiVars map[string]string
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse and manage prompts in a full-screen terminal UI",
+	Long: `Open a full-screen browser for your prompts.
+
+The left pane shows the context tree, the middle pane the prompts in the
+highlighted context, and the right pane the metadata and full content of the
+highlighted prompt, wrapped to fit.
+
+Keys:
+  tab, h/l, ←/→   switch between the context tree and the prompt list
+  ↑/↓, j/k        move in the focused pane
+  pgup/pgdn, J/K  scroll the preview
+  /               search as you type (enter keeps the filter, esc clears it)
+  T, #, P         cycle the type, tag and project filters
+  x               clear the filters
+  enter           copy the highlighted prompt and quit
+  n               write a new prompt in the highlighted context
+  e, d, m, y, c   edit, delete, move, copy or duplicate the highlighted prompt
+  q, esc          quit`,
+	Example: `  pmt ui
+  pmt ui --to tmux
+  pmt ui --var lang=go`,
+	Args: cobra.NoArgs,
+	RunE: runUI,
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+	uiCmd.Flags().StringToStringVar(&uiVars, "var", nil, "Set a template variable (name=value) for copied prompts")
+	uiCmd.Flags().StringVar(&uiTo, "to", "", "Clipboard backend: auto, system, xclip, xsel, wl-copy, pbcopy, osc52, tmux, stdout, file:<path>")
+}
+
+func runUI(cmd *cobra.Command, args []string) error {
+	backend, err := clipboardBackend(uiTo, false)
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+	setTypeColors(store)
+
+	loadPrompts := func() ([]models.Prompt, error) {
+		prompts, err := store.Filter(storage.FilterOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load prompts: %w", err)
+		}
+		return prompts, nil
+	}
+
+	prompts, err := loadPrompts()
+	if err != nil {
+		return err
+	}
+
+	ropts := renderOptions{MaxBytes: render.DefaultMaxBytes, Vars: uiVars}
+	selected, err := ui.Browse(prompts, ui.BrowserOptions{
+		Actions: pickerActions(store, backend, ropts),
+		Create: func(context string) (string, error) {
+			return createPrompt(store, context)
+		},
+		Reload: loadPrompts,
+	})
+	if err != nil {
+		return err
+	}
+	if selected == nil {
+		return nil
+	}
+
+	content, err := buildOutput(store, []*models.Prompt{selected}, true, outputOptions{
+		Render: ropts,
+		Format: render.FormatPlain,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := copyText(backend, content); err != nil {
+		return err
+	}
+
+	out := statusWriter(backend)
+	fmt.Fprintf(out, "✓ Copied to clipboard (%s): %s\n", backend.Name(), selected.ID)
+	fmt.Fprintln(out, "💡 Now paste (Ctrl+V) into Copilot!")
+	return nil
+}
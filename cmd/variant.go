@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/storage"
+)
+
+var variantCmd = &cobra.Command{
+	Use:   "variant",
+	Short: "Manage named variants of a prompt",
+	Long: `Manage named variants of a prompt.
+
+A variant is an alternative content for the same prompt, such as a "terse"
+and a "detailed" version, or one per target model. Use 'pmt apply --variant'
+to pick one, or choose it interactively after selecting the prompt.`,
+	Example: `  pmt variant list a7f
+  pmt variant set a7f terse "Fix the leak. Be brief."
+  pmt variant set a7f gpt-4   # Opens editor
+  pmt variant rm a7f terse`,
+}
+
+var variantListCmd = &cobra.Command{
+	Use:     "list <id>",
+	Aliases: []string{"ls"},
+	Short:   "List the variants of a prompt",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runVariantList,
+}
+
+var variantSetCmd = &cobra.Command{
+	Use:   "set <id> <name> [content]",
+	Short: "Add or replace a variant",
+	Long: `Add or replace a named variant of a prompt.
+
+If no content is provided, an editor will open for you to write it.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runVariantSet,
+}
+
+var variantRmCmd = &cobra.Command{
+	Use:     "rm <id> <name>",
+	Aliases: []string{"delete"},
+	Short:   "Remove a variant",
+	Args:    cobra.ExactArgs(2),
+	RunE:    runVariantRm,
+}
+
+func init() {
+	rootCmd.AddCommand(variantCmd)
+	variantCmd.AddCommand(variantListCmd)
+	variantCmd.AddCommand(variantSetCmd)
+	variantCmd.AddCommand(variantRmCmd)
+}
+
+func runVariantList(cmd *cobra.Command, args []string) error {
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	prompt, err := store.FindByID(args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(prompt.Variants) == 0 {
+		fmt.Printf("Prompt %s has no variants.\n", prompt.ID)
+		return nil
+	}
+
+	fmt.Printf("%-20s %s\n", "Variant", "Content")
+	fmt.Println(strings.Repeat("-", 60))
+	for _, name := range prompt.VariantNames() {
+		fmt.Printf("%-20s %s\n", truncateString(name, 20), truncateString(prompt.Variants[name], 40))
+	}
+
+	fmt.Printf("\nTotal: %d variant%s\n", len(prompt.Variants), pluralize(len(prompt.Variants)))
+	return nil
+}
+
+func runVariantSet(cmd *cobra.Command, args []string) error {
+	id, name := args[0], strings.TrimSpace(args[1])
+	if name == "" || name == "default" || name == "(default)" {
+		return fmt.Errorf("invalid variant name: %q", args[1])
+	}
+
+	var content string
+	if len(args) == 2 {
+		var err error
+		content, err = openEditor("")
+		if err != nil {
+			return fmt.Errorf("failed to open editor: %w", err)
+		}
+	} else {
+		content = strings.Join(args[2:], " ")
+	}
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return fmt.Errorf("variant content cannot be empty")
+	}
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	prompt, err := store.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if prompt.IsChat() {
+		return fmt.Errorf("chat prompt %s cannot have variants", prompt.ID)
+	}
+
+	_, replaced := prompt.Variants[name]
+	err = store.Update(prompt.ID, func(p *models.Prompt) {
+		if p.Variants == nil {
+			p.Variants = make(map[string]string)
+		}
+		p.Variants[name] = content
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save variant: %w", err)
+	}
+
+	if replaced {
+		fmt.Printf("✓ Updated variant %s of prompt %s\n", name, prompt.ID)
+	} else {
+		fmt.Printf("✓ Added variant %s to prompt %s\n", name, prompt.ID)
+	}
+	return nil
+}
+
+func runVariantRm(cmd *cobra.Command, args []string) error {
+	id, name := args[0], args[1]
+
+	store, err := storage.NewFileStore()
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+
+	prompt, err := store.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := prompt.Variants[name]; !ok {
+		return fmt.Errorf("prompt %s has no variant %q", prompt.ID, name)
+	}
+
+	err = store.Update(prompt.ID, func(p *models.Prompt) {
+		delete(p.Variants, name)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove variant: %w", err)
+	}
+
+	fmt.Printf("✓ Removed variant %s from prompt %s\n", name, prompt.ID)
+	return nil
+}
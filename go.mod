@@ -3,12 +3,13 @@ module github.com/sunny/pmt
 go 1.22.1
 
 require (
-	github.com/atotto/clipboard v0.1.4 // indirect
-	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e // indirect
+	github.com/atotto/clipboard v0.1.4
+	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e
+	github.com/spf13/cobra v1.10.2
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/manifoldco/promptui v0.9.0 // indirect
-	github.com/spf13/cobra v1.10.2 // indirect
 	github.com/spf13/pflag v1.0.9 // indirect
-	golang.org/x/sys v0.0.0-20181122145206-62eef0e2fa9b // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
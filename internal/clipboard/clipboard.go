@@ -0,0 +1,245 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	sysclipboard "github.com/atotto/clipboard"
+)
+
+// Backend copies text to a clipboard
+type Backend interface {
+	Name() string
+	Write(text string) error
+}
+
+// Reader is implemented by backends that can read the current clipboard contents
+type Reader interface {
+	Read() (string, error)
+}
+
+// Names lists the backend names accepted by New
+func Names() []string {
+	return []string{"auto", "system", "xclip", "xsel", "wl-copy", "pbcopy", "osc52", "tmux", "stdout", "file:<path>"}
+}
+
+// New returns the backend with the given name. "auto" or an empty name detects one.
+func New(name string) (Backend, error) {
+	switch {
+	case name == "" || name == "auto":
+		return Detect(), nil
+	case name == "system":
+		return systemBackend{}, nil
+	case name == "xclip":
+		return &commandBackend{name: "xclip",
+			write: []string{"xclip", "-selection", "clipboard"},
+			read:  []string{"xclip", "-selection", "clipboard", "-o"}}, nil
+	case name == "xsel":
+		return &commandBackend{name: "xsel",
+			write: []string{"xsel", "--clipboard", "--input"},
+			read:  []string{"xsel", "--clipboard", "--output"}}, nil
+	case name == "wl-copy":
+		return &commandBackend{name: "wl-copy",
+			write: []string{"wl-copy"},
+			read:  []string{"wl-paste", "--no-newline"}}, nil
+	case name == "pbcopy":
+		return &commandBackend{name: "pbcopy",
+			write: []string{"pbcopy"},
+			read:  []string{"pbpaste"}}, nil
+	case name == "tmux":
+		return &commandBackend{name: "tmux",
+			write: []string{"tmux", "load-buffer", "-"},
+			read:  []string{"tmux", "save-buffer", "-"},
+			empty: "no buffers"}, nil
+	case name == "osc52":
+		return osc52Backend{}, nil
+	case name == "stdout":
+		return stdoutBackend{}, nil
+	case strings.HasPrefix(name, "file:"):
+		path := strings.TrimPrefix(name, "file:")
+		if path == "" {
+			return nil, fmt.Errorf("file backend needs a path, e.g. file:/tmp/prompt.txt")
+		}
+		return fileBackend{path: path}, nil
+	}
+
+	return nil, fmt.Errorf("unknown clipboard backend: %s (must be one of %s)", name, strings.Join(Names(), ", "))
+}
+
+// Detect picks the most suitable backend for the current environment.
+// Local clipboards win when a display is available; remote sessions fall back
+// to OSC 52 through the terminal, then to the tmux buffer, then to stdout.
+func Detect() Backend {
+	switch runtime.GOOS {
+	case "darwin":
+		return mustNew("pbcopy")
+	case "windows":
+		return systemBackend{}
+	}
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" && hasCommand("wl-copy") {
+		return mustNew("wl-copy")
+	}
+	if os.Getenv("DISPLAY") != "" {
+		if hasCommand("xclip") {
+			return mustNew("xclip")
+		}
+		if hasCommand("xsel") {
+			return mustNew("xsel")
+		}
+	}
+
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		tty.Close()
+		return osc52Backend{}
+	}
+	if os.Getenv("TMUX") != "" && hasCommand("tmux") {
+		return mustNew("tmux")
+	}
+	return stdoutBackend{}
+}
+
+// DetectReader picks the most suitable backend that can also read the
+// clipboard back, or returns nil when there is none. It differs from Detect in
+// remote sessions, where OSC 52 can only write and the tmux buffer is used instead.
+func DetectReader() Backend {
+	if b := Detect(); isReader(b) {
+		return b
+	}
+	if os.Getenv("TMUX") != "" && hasCommand("tmux") {
+		return mustNew("tmux")
+	}
+	return nil
+}
+
+func isReader(b Backend) bool {
+	_, ok := b.(Reader)
+	return ok
+}
+
+func mustNew(name string) Backend {
+	b, err := New(name)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// commandBackend pipes text into an external clipboard tool
+type commandBackend struct {
+	name  string
+	write []string
+	read  []string
+	empty string // error output of the read command that means the clipboard is empty
+}
+
+func (b *commandBackend) Name() string { return b.name }
+
+func (b *commandBackend) Write(text string) error {
+	cmd := exec.Command(b.write[0], b.write[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", b.name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (b *commandBackend) Read() (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(b.read[0], b.read[1:]...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if b.empty != "" && strings.Contains(stderr.String(), b.empty) {
+			return "", nil
+		}
+		return "", fmt.Errorf("%s failed to read clipboard: %w: %s", b.name, err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+// systemBackend uses the platform clipboard library
+type systemBackend struct{}
+
+func (systemBackend) Name() string            { return "system" }
+func (systemBackend) Write(text string) error { return sysclipboard.WriteAll(text) }
+func (systemBackend) Read() (string, error)   { return sysclipboard.ReadAll() }
+
+// osc52Backend asks the terminal emulator to set the clipboard with an OSC 52
+// escape sequence, which also works over SSH and inside containers
+type osc52Backend struct{}
+
+func (osc52Backend) Name() string { return "osc52" }
+
+func (osc52Backend) Write(text string) error {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+
+	// tmux and screen only forward escape sequences wrapped in a DCS passthrough
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = "\x1bP" + seq + "\x1b\\"
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("osc52 needs a terminal: %w", err)
+	}
+	defer tty.Close()
+
+	if _, err := tty.WriteString(seq); err != nil {
+		return fmt.Errorf("failed to write OSC 52 sequence: %w", err)
+	}
+	return nil
+}
+
+// stdoutBackend prints the text, for piping into other tools
+type stdoutBackend struct{}
+
+func (stdoutBackend) Name() string { return "stdout" }
+
+func (stdoutBackend) Write(text string) error {
+	_, err := fmt.Fprintln(os.Stdout, text)
+	return err
+}
+
+// fileBackend writes the text to a file
+type fileBackend struct {
+	path string
+}
+
+func (b fileBackend) Name() string { return "file:" + b.path }
+
+func (b fileBackend) Write(text string) error {
+	if err := os.WriteFile(b.path, []byte(text), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", b.path, err)
+	}
+	return nil
+}
+
+func (b fileBackend) Read() (string, error) {
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", b.path, err)
+	}
+	return string(data), nil
+}
+
+// IsStdout reports whether the backend writes to standard output
+func IsStdout(b Backend) bool {
+	_, ok := b.(stdoutBackend)
+	return ok
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the user's settings from ~/.pmt/config.yaml
+type Config struct {
+	Runner      string            `yaml:"runner,omitempty"`       // default runner profile (or command) for 'pmt run'
+	Runners     map[string]string `yaml:"runners,omitempty"`      // named runner profiles, e.g. "ollama": "ollama run llama3"
+	TypeRunners map[string]string `yaml:"type_runners,omitempty"` // runner profile (or command) per prompt type
+	Clipboard   string            `yaml:"clipboard,omitempty"`    // clipboard backend; empty or "auto" detects one
+	Scope       string            `yaml:"scope,omitempty"`        // default scope of apply, pop and list: "project" (default) or "all"
+	Editor      string            `yaml:"editor,omitempty"`       // editor command; empty uses $EDITOR, $VISUAL or vim
+	DefaultType string            `yaml:"default_type,omitempty"` // type of pushed prompts without --type
+	ListColumns []string          `yaml:"list_columns,omitempty"` // columns of 'pmt list'
+	DateFormat  string            `yaml:"date_format,omitempty"`  // Go time layout for dates
+	Color       string            `yaml:"color,omitempty"`        // "auto" (default), "on" or "off"
+	PageSize    int               `yaml:"page_size,omitempty"`    // prompts shown at once in selectors
+	Store       string            `yaml:"store,omitempty"`        // storage backend; only "file"
+	Profile     string            `yaml:"profile,omitempty"`      // separate prompt store in ~/.pmt/profiles/<name>
+	TagCase     string            `yaml:"tag_case,omitempty"`     // "lower" (default) or "preserve"
+	TagSynonyms map[string]string `yaml:"tag_synonyms,omitempty"` // tags replaced by another tag, e.g. "pg": "postgres"
+}
+
+// Scope values
+const (
+	ScopeProject = "project"
+	ScopeAll     = "all"
+)
+
+// AllProjects reports whether apply, pop and list show every project's
+// prompts by default instead of only the current project's
+func (c *Config) AllProjects() (bool, error) {
+	switch c.Scope {
+	case "", ScopeProject:
+		return false, nil
+	case ScopeAll:
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid scope in config: %s (must be project or all)", c.Scope)
+}
+
+// Path returns the location of the user configuration file
+func Path() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".pmt", "config.yaml"), nil
+}
+
+// Load returns the effective configuration. Settings are taken, from highest
+// to lowest precedence, from command line flags, PMT_* environment
+// variables, the repository's .pmt.yaml and the user's ~/.pmt/config.yaml.
+func Load() (*Config, error) {
+	cfg, err := LoadUser()
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := CurrentRepo()
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Config.validate(filepath.Join(repo.root, RepoFile)); err != nil {
+		return nil, err
+	}
+	cfg.merge(&repo.Config)
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadUser reads the user configuration only. A missing file yields an empty configuration.
+func LoadUser() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	return Parse(data, path)
+}
+
+// Parse reads and validates a user configuration file's content; source names
+// the file in errors
+func Parse(data []byte, source string) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", source, err)
+	}
+	if err := cfg.validate(source); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// SaveUser writes the user configuration to ~/.pmt/config.yaml
+func SaveUser(cfg *Config) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// merge overrides the settings of c with those set in o. Runner profiles,
+// per-type runners and tag synonyms are merged by name.
+func (c *Config) merge(o *Config) {
+	for i := range Settings {
+		if v := Settings[i].get(o); v != "" {
+			Settings[i].set(c, v)
+		}
+	}
+	if len(o.Runners) > 0 && c.Runners == nil {
+		c.Runners = make(map[string]string)
+	}
+	for name, command := range o.Runners {
+		c.Runners[name] = command
+	}
+	if len(o.TypeRunners) > 0 && c.TypeRunners == nil {
+		c.TypeRunners = make(map[string]string)
+	}
+	for promptType, runner := range o.TypeRunners {
+		c.TypeRunners[promptType] = runner
+	}
+	if len(o.TagSynonyms) > 0 && c.TagSynonyms == nil {
+		c.TagSynonyms = make(map[string]string)
+	}
+	for synonym, tag := range o.TagSynonyms {
+		c.TagSynonyms[synonym] = tag
+	}
+}
+
+// RunnerFor returns the shell command used to run a prompt of the given type.
+// The override (from --runner) wins over the per-type runner, which wins over
+// the default runner. Each of them may name a runner profile or be a literal command.
+func (c *Config) RunnerFor(promptType, override string) (string, error) {
+	name := override
+	if name == "" {
+		name = c.TypeRunners[promptType]
+	}
+	if name == "" {
+		name = c.Runner
+	}
+	if name == "" {
+		return "", fmt.Errorf("no runner configured: set 'runner' in ~/.pmt/config.yaml or pass --runner")
+	}
+
+	if command, ok := c.Runners[name]; ok {
+		return command, nil
+	}
+	return name, nil
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sunny/pmt/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// RepoFile is the name of the repository configuration file, kept at the git toplevel
+const RepoFile = ".pmt.yaml"
+
+// RepoConfig holds a repository's settings from its .pmt.yaml. User settings
+// other than the UserOnly ones can be given there too and override the user's
+// own for the repository.
+type RepoConfig struct {
+	Config `yaml:",inline"`
+
+	Name         string        `yaml:"name,omitempty"`          // display name of the project, instead of the folder name
+	Context      string        `yaml:"context,omitempty"`       // context of prompts pushed at the repository root
+	Tags         []string      `yaml:"tags,omitempty"`          // tags added to every prompt pushed in the repository
+	Types        []string      `yaml:"types,omitempty"`         // the only types prompts of the repository may have
+	PromptDir    string        `yaml:"prompt_dir,omitempty"`    // directory, relative to the root, that holds the repository's own prompt store
+	InferContext *bool         `yaml:"infer_context,omitempty"` // derive the default context from the working directory (default true)
+	Contexts     []ContextRule `yaml:"contexts,omitempty"`      // directory to context mappings for the inferred context
+
+	root string
+}
+
+// ContextRule maps a directory of the repository, and everything below it,
+// to a context. An empty context means no context.
+type ContextRule struct {
+	Path    string `yaml:"path"`    // directory relative to the repository root, e.g. "services/billing"
+	Context string `yaml:"context"` // context used in that directory, e.g. "billing"
+}
+
+// LoadRepo reads the .pmt.yaml in the given repository root. A missing file
+// yields an empty configuration.
+func LoadRepo(root string) (*RepoConfig, error) {
+	path := filepath.Join(root, RepoFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &RepoConfig{root: root}, nil
+		}
+		return nil, fmt.Errorf("failed to read repository config: %w", err)
+	}
+
+	var cfg RepoConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse repository config %s: %w", path, err)
+	}
+	cfg.root = root
+	if err := cfg.checkKeys(path); err != nil {
+		return nil, err
+	}
+	if err := cfg.checkPromptDir(path); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// checkKeys rejects the settings a repository may not choose for the people
+// who clone it: commands to run, and where prompts are stored
+func (c *RepoConfig) checkKeys(path string) error {
+	var keys []string
+	for i := range Settings {
+		if Settings[i].UserOnly && Settings[i].get(&c.Config) != "" {
+			keys = append(keys, Settings[i].Key)
+		}
+	}
+	if len(c.Runners) > 0 {
+		keys = append(keys, "runners")
+	}
+	if len(c.TypeRunners) > 0 {
+		keys = append(keys, "type_runners")
+	}
+	if len(keys) > 0 {
+		return fmt.Errorf("%s cannot set %s; these settings run commands or choose the prompt store, so they only belong in ~/.pmt/config.yaml", path, strings.Join(keys, ", "))
+	}
+	return nil
+}
+
+// checkPromptDir makes sure prompt_dir names a directory inside the
+// repository, also after following symlinks, since the store is created there
+func (c *RepoConfig) checkPromptDir(path string) error {
+	if c.PromptDir == "" {
+		return nil
+	}
+	invalid := fmt.Errorf("invalid prompt_dir in %s: %s: must be a relative path inside the repository", path, c.PromptDir)
+	if !filepath.IsLocal(c.PromptDir) {
+		return invalid
+	}
+
+	// The directory may not exist yet, so check the part of it that does
+	dir := filepath.Join(c.root, c.PromptDir)
+	for {
+		if _, err := os.Lstat(dir); err == nil || dir == c.root {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	root, err := filepath.EvalSymlinks(c.root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve repository root: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return invalid
+	}
+	if rel, err := filepath.Rel(root, resolved); err != nil || (rel != "." && !filepath.IsLocal(rel)) {
+		return invalid
+	}
+	return nil
+}
+
+// CurrentRepo reads the .pmt.yaml of the current git repository. Outside a
+// repository, or without the file, it yields an empty configuration. The file
+// is read once per process; callers must not modify the result.
+func CurrentRepo() (*RepoConfig, error) {
+	return currentRepo()
+}
+
+var currentRepo = sync.OnceValues(func() (*RepoConfig, error) {
+	root, err := utils.GitRoot()
+	if err != nil {
+		return &RepoConfig{}, nil
+	}
+	return LoadRepo(root)
+})
+
+// StorePath returns the repository's own prompt store, or "" when its
+// prompts are kept in the user's store
+func (c *RepoConfig) StorePath() string {
+	if c.PromptDir == "" || c.root == "" {
+		return ""
+	}
+	return filepath.Join(c.root, c.PromptDir, "prompts.yaml")
+}
+
+// AllowsType reports whether prompts of the repository may have the given type
+func (c *RepoConfig) AllowsType(t string) bool {
+	if len(c.Types) == 0 {
+		return true
+	}
+	for _, allowed := range c.Types {
+		if strings.EqualFold(allowed, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContextFor returns the context inferred for a directory, given as a
+// slash-separated path relative to the repository root. The rule with the
+// longest matching path wins, and the rest of the directory is appended to
+// its context; without a matching rule the directory itself is the context.
+// Example: with the rule services/billing -> billing, "services/billing/api"
+// becomes "billing/api". At the root, or with inference turned off, it is
+// the configured default context.
+func (c *RepoConfig) ContextFor(dir string) string {
+	dir = strings.Trim(dir, "/")
+	if dir == "" || (c.InferContext != nil && !*c.InferContext) {
+		return c.Context
+	}
+
+	context := dir
+	longest := -1
+	for _, rule := range c.Contexts {
+		path := strings.Trim(rule.Path, "/")
+		if path != "" && dir != path && !strings.HasPrefix(dir, path+"/") {
+			continue
+		}
+		if len(path) <= longest {
+			continue
+		}
+		longest = len(path)
+
+		remainder := strings.Trim(strings.TrimPrefix(dir, path), "/")
+		switch {
+		case rule.Context == "":
+			context = ""
+		case remainder == "":
+			context = rule.Context
+		default:
+			context = rule.Context + "/" + remainder
+		}
+	}
+	return context
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestContextFor(t *testing.T) {
+	off := false
+	rules := []ContextRule{
+		{Path: "services", Context: "svc"},
+		{Path: "services/billing", Context: "billing"},
+		{Path: "vendor", Context: ""},
+		{Path: "/docs/", Context: "documentation"},
+	}
+
+	tests := []struct {
+		name string
+		cfg  RepoConfig
+		dir  string
+		want string
+	}{
+		{
+			name: "toplevel uses the default context",
+			cfg:  RepoConfig{Context: "general"},
+			dir:  "",
+			want: "general",
+		},
+		{
+			name: "without rules the directory is the context",
+			cfg:  RepoConfig{},
+			dir:  "backend/api",
+			want: "backend/api",
+		},
+		{
+			name: "rule for the directory itself",
+			cfg:  RepoConfig{Contexts: rules},
+			dir:  "services",
+			want: "svc",
+		},
+		{
+			name: "sub-directories keep the rest of the path",
+			cfg:  RepoConfig{Contexts: rules},
+			dir:  "services/auth/tokens",
+			want: "svc/auth/tokens",
+		},
+		{
+			name: "longest matching rule wins",
+			cfg:  RepoConfig{Contexts: rules},
+			dir:  "services/billing/invoices",
+			want: "billing/invoices",
+		},
+		{
+			name: "rule paths match whole directories only",
+			cfg:  RepoConfig{Contexts: rules},
+			dir:  "services-old",
+			want: "services-old",
+		},
+		{
+			name: "empty context means no context",
+			cfg:  RepoConfig{Contexts: rules},
+			dir:  "vendor/lib",
+			want: "",
+		},
+		{
+			name: "slashes around paths are ignored",
+			cfg:  RepoConfig{Contexts: rules},
+			dir:  "/docs/guides/",
+			want: "documentation/guides",
+		},
+		{
+			name: "inference can be turned off",
+			cfg:  RepoConfig{Context: "general", InferContext: &off, Contexts: rules},
+			dir:  "services/billing",
+			want: "general",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.ContextFor(tt.dir); got != tt.want {
+				t.Errorf("ContextFor(%q) = %q, want %q", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRepoRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"editor", "editor: /tmp/evil.sh\n"},
+		{"runner", "runner: sh -c evil\n"},
+		{"runners", "runners:\n  x: evil\n"},
+		{"type_runners", "type_runners:\n  bugfix: evil\n"},
+		{"profile", "profile: other\n"},
+		{"clipboard", "clipboard: osc52\n"},
+		{"absolute prompt_dir", "prompt_dir: /tmp/prompts\n"},
+		{"prompt_dir outside the root", "prompt_dir: ../../tmp/escaped\n"},
+		{"prompt_dir through a symlink", "prompt_dir: link/prompts\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.Symlink(t.TempDir(), filepath.Join(root, "link")); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(root, RepoFile), []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadRepo(root); err == nil {
+				t.Errorf("LoadRepo accepted %q", tt.yaml)
+			}
+		})
+	}
+}
+
+func TestLoadRepoAccepts(t *testing.T) {
+	root := t.TempDir()
+	data := "name: api\ncontext: general\ntags: [go]\ntypes: [bugfix]\nprompt_dir: .pmt/prompts\ndate_format: \"2006-01-02\"\n"
+	if err := os.WriteFile(filepath.Join(root, RepoFile), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadRepo(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, ".pmt", "prompts", "prompts.yaml"); cfg.StorePath() != want {
+		t.Errorf("StorePath() = %q, want %q", cfg.StorePath(), want)
+	}
+}
@@ -0,0 +1,504 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunny/pmt/internal/clipboard"
+	"github.com/sunny/pmt/internal/models"
+)
+
+// Setting is a configuration key that 'pmt config' can get and set
+type Setting struct {
+	Key     string
+	Env     string // environment variable that overrides the configuration files
+	Default string // effective value when the key is not set anywhere
+	Usage   string
+
+	// UserOnly keys run commands or choose where prompts are kept, so a
+	// repository's .pmt.yaml cannot set them
+	UserOnly bool
+
+	get func(c *Config) string
+	set func(c *Config, value string) error // validates and stores a value; "" unsets it
+}
+
+// Columns are the columns 'pmt list' can show
+var Columns = []string{"id", "name", "type", "project", "context", "branch", "tags", "content", "created"}
+
+// Defaults of the settings that have one
+const (
+	DefaultDateFormat = "2006-01-02 15:04"
+	DefaultPageSize   = 10
+	DefaultColumns    = "id,name,type,project,context,content,created"
+	StoreFile         = "file"
+)
+
+// Tag case values
+const (
+	TagCaseLower    = "lower"
+	TagCasePreserve = "preserve"
+)
+
+// Color values
+const (
+	ColorAuto = "auto"
+	ColorOn   = "on"
+	ColorOff  = "off"
+)
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Settings lists every key in the order 'pmt config list' shows them
+var Settings = []Setting{
+	{
+		Key:      "editor",
+		UserOnly: true,
+		Env:      "PMT_EDITOR",
+		Default:  "$EDITOR, $VISUAL or vim",
+		Usage:    "command that opens prompts for editing, e.g. \"code --wait\"; quote paths with spaces",
+		get:      func(c *Config) string { return c.Editor },
+		set: func(c *Config, v string) error {
+			if v != "" {
+				if _, err := splitCommand(v); err != nil {
+					return err
+				}
+			}
+			c.Editor = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		Key:     "default_type",
+		Env:     "PMT_DEFAULT_TYPE",
+		Default: "general",
+		Usage:   "type of pushed prompts without --type, a name from 'pmt type list'",
+		get:     func(c *Config) string { return c.DefaultType },
+		set: func(c *Config, v string) error {
+			if v == "" {
+				c.DefaultType = ""
+				return nil
+			}
+			name, err := models.NormalizeTypeName(v)
+			if err != nil {
+				return fmt.Errorf("must be a type name such as general")
+			}
+			c.DefaultType = name
+			return nil
+		},
+	},
+	{
+		Key:      "clipboard",
+		UserOnly: true,
+		Env:      "PMT_CLIPBOARD",
+		Default:  "auto",
+		Usage:    "clipboard backend: " + strings.Join(clipboard.Names(), ", "),
+		get:      func(c *Config) string { return c.Clipboard },
+		set: func(c *Config, v string) error {
+			if v != "" {
+				if _, err := clipboard.New(v); err != nil {
+					return fmt.Errorf("must be one of %s", strings.Join(clipboard.Names(), ", "))
+				}
+			}
+			c.Clipboard = v
+			return nil
+		},
+	},
+	{
+		Key:     "scope",
+		Env:     "PMT_SCOPE",
+		Default: ScopeProject,
+		Usage:   "prompts offered by apply, pop and list: project or all",
+		get:     func(c *Config) string { return c.Scope },
+		set: func(c *Config, v string) error {
+			if v != "" && v != ScopeProject && v != ScopeAll {
+				return fmt.Errorf("must be project or all")
+			}
+			c.Scope = v
+			return nil
+		},
+	},
+	{
+		Key:     "list_columns",
+		Env:     "PMT_LIST_COLUMNS",
+		Default: DefaultColumns,
+		Usage:   "columns of 'pmt list', comma-separated: " + strings.Join(Columns, ", "),
+		get:     func(c *Config) string { return strings.Join(c.ListColumns, ",") },
+		set: func(c *Config, v string) error {
+			var columns []string
+			for _, column := range strings.Split(v, ",") {
+				column = strings.ToLower(strings.TrimSpace(column))
+				if column == "" {
+					continue
+				}
+				if !contains(Columns, column) {
+					return fmt.Errorf("unknown column %s (must be %s)", column, strings.Join(Columns, ", "))
+				}
+				columns = append(columns, column)
+			}
+			c.ListColumns = columns
+			return nil
+		},
+	},
+	{
+		Key:     "date_format",
+		Env:     "PMT_DATE_FORMAT",
+		Default: DefaultDateFormat,
+		Usage:   "Go time layout for dates, e.g. \"Jan 2 15:04\"",
+		get:     func(c *Config) string { return c.DateFormat },
+		set: func(c *Config, v string) error {
+			if v != "" && time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC).Format(v) == v {
+				return fmt.Errorf("must be a Go time layout such as %s", DefaultDateFormat)
+			}
+			c.DateFormat = v
+			return nil
+		},
+	},
+	{
+		Key:     "color",
+		Env:     "PMT_COLOR",
+		Default: ColorAuto,
+		Usage:   "colors in selectors: auto (off when NO_COLOR is set), on or off",
+		get:     func(c *Config) string { return c.Color },
+		set: func(c *Config, v string) error {
+			switch v {
+			case "", ColorAuto, ColorOn, ColorOff:
+				c.Color = v
+				return nil
+			}
+			return fmt.Errorf("must be auto, on or off")
+		},
+	},
+	{
+		Key:     "page_size",
+		Env:     "PMT_PAGE_SIZE",
+		Default: strconv.Itoa(DefaultPageSize),
+		Usage:   "prompts shown at once in selectors, 1 to 100",
+		get: func(c *Config) string {
+			if c.PageSize == 0 {
+				return ""
+			}
+			return strconv.Itoa(c.PageSize)
+		},
+		set: func(c *Config, v string) error {
+			if v == "" {
+				c.PageSize = 0
+				return nil
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 100 {
+				return fmt.Errorf("must be a number from 1 to 100")
+			}
+			c.PageSize = n
+			return nil
+		},
+	},
+	{
+		Key:     "tag_case",
+		Env:     "PMT_TAG_CASE",
+		Default: TagCaseLower,
+		Usage:   "case of stored tags: lower or preserve",
+		get:     func(c *Config) string { return c.TagCase },
+		set: func(c *Config, v string) error {
+			if v != "" && v != TagCaseLower && v != TagCasePreserve {
+				return fmt.Errorf("must be lower or preserve")
+			}
+			c.TagCase = v
+			return nil
+		},
+	},
+	{
+		Key:      "store",
+		UserOnly: true,
+		Env:      "PMT_STORE",
+		Default:  StoreFile,
+		Usage:    "storage backend; only file (a YAML file under ~/.pmt) is available",
+		get:      func(c *Config) string { return c.Store },
+		set: func(c *Config, v string) error {
+			if v != "" && v != StoreFile {
+				return fmt.Errorf("unsupported store backend (only %s is available)", StoreFile)
+			}
+			c.Store = v
+			return nil
+		},
+	},
+	{
+		Key:      "profile",
+		UserOnly: true,
+		Env:      "PMT_PROFILE",
+		Default:  "",
+		Usage:    "separate prompt store in ~/.pmt/profiles/<name>, e.g. work or personal",
+		get:      func(c *Config) string { return c.Profile },
+		set: func(c *Config, v string) error {
+			if v != "" && !profileName.MatchString(v) {
+				return fmt.Errorf("must contain only letters, digits, - and _")
+			}
+			c.Profile = v
+			return nil
+		},
+	},
+	{
+		Key:      "runner",
+		UserOnly: true,
+		Env:      "PMT_RUNNER",
+		Default:  "",
+		Usage:    "default runner profile or command for 'pmt run'",
+		get:      func(c *Config) string { return c.Runner },
+		set: func(c *Config, v string) error {
+			c.Runner = v
+			return nil
+		},
+	},
+}
+
+// LookupSetting returns the setting with the given key
+func LookupSetting(key string) (*Setting, error) {
+	for i := range Settings {
+		if Settings[i].Key == key {
+			return &Settings[i], nil
+		}
+	}
+	keys := make([]string, len(Settings))
+	for i, s := range Settings {
+		keys[i] = s.Key
+	}
+	return nil, fmt.Errorf("unknown config key: %s (must be one of %s)", key, strings.Join(keys, ", "))
+}
+
+// Get returns the value of the setting in c, or "" if it is not set
+func (s *Setting) Get(c *Config) string {
+	return s.get(c)
+}
+
+// Set validates a value and stores it in c; an empty value unsets the key
+func (s *Setting) Set(c *Config, value string) error {
+	if err := s.set(c, strings.TrimSpace(value)); err != nil {
+		return fmt.Errorf("invalid %s: %s: %w", s.Key, value, err)
+	}
+	return nil
+}
+
+// Resolve returns the effective value of a setting and where it comes from:
+// a flag, the environment, the repository's .pmt.yaml, the user's config
+// file, or the default
+func (s *Setting) Resolve() (value, source string, err error) {
+	if v, ok := overrides[s.Key]; ok {
+		return v, "flag", nil
+	}
+	if v := os.Getenv(s.Env); v != "" {
+		return v, "env " + s.Env, nil
+	}
+
+	repo, err := CurrentRepo()
+	if err != nil {
+		return "", "", err
+	}
+	if v := s.get(&repo.Config); v != "" && !s.UserOnly {
+		return v, RepoFile, nil
+	}
+
+	user, err := LoadUser()
+	if err != nil {
+		return "", "", err
+	}
+	if v := s.get(user); v != "" {
+		return v, "user config", nil
+	}
+
+	return s.Default, "default", nil
+}
+
+// overrides holds settings given as command line flags
+var overrides = make(map[string]string)
+
+// Override sets a key from a command line flag, taking precedence over the
+// environment and configuration files
+func Override(key, value string) error {
+	s, err := LookupSetting(key)
+	if err != nil {
+		return err
+	}
+	if err := s.Set(&Config{}, value); err != nil {
+		return err
+	}
+	overrides[key] = value
+	return nil
+}
+
+// validate checks the settings read from a configuration file
+func (c *Config) validate(source string) error {
+	for i := range Settings {
+		s := &Settings[i]
+		if v := s.get(c); v != "" {
+			if err := s.Set(c, v); err != nil {
+				return fmt.Errorf("%w (in %s)", err, source)
+			}
+		}
+	}
+	for synonym, tag := range c.TagSynonyms {
+		if strings.Trim(strings.TrimSpace(synonym), "/") == "" || strings.Trim(strings.TrimSpace(tag), "/") == "" {
+			return fmt.Errorf("invalid tag_synonyms: %q: %q: synonyms and tags cannot be empty (in %s)", synonym, tag, source)
+		}
+	}
+	return nil
+}
+
+// applyEnv applies the settings given in environment variables, then those
+// given as flags
+func (c *Config) applyEnv() error {
+	for i := range Settings {
+		s := &Settings[i]
+		if v := os.Getenv(s.Env); v != "" {
+			if err := s.Set(c, v); err != nil {
+				return fmt.Errorf("%w (from %s)", err, s.Env)
+			}
+		}
+		if v, ok := overrides[s.Key]; ok {
+			if err := s.Set(c, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// EditorCommand returns the editor command line: the editor setting, split
+// like a shell would, or else $EDITOR, $VISUAL and finally vim, each taken as
+// the path of a single program
+func (c *Config) EditorCommand() ([]string, error) {
+	if c.Editor != "" {
+		return splitCommand(c.Editor)
+	}
+	for _, env := range []string{"EDITOR", "VISUAL"} {
+		if editor := os.Getenv(env); editor != "" {
+			return []string{editor}, nil
+		}
+	}
+	return []string{"vim"}, nil
+}
+
+// splitCommand splits a command line into words the way a shell would,
+// without expansions: words are separated by unquoted blanks, single quotes
+// keep everything literally, and a backslash escapes the next character,
+// within double quotes only if it is one of $ ` " \
+func splitCommand(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", runes[i+1]) {
+				word.WriteRune(r)
+				continue
+			}
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return words, nil
+}
+
+// PromptType returns the type of pushed prompts without --type
+func (c *Config) PromptType() string {
+	if c.DefaultType != "" {
+		return c.DefaultType
+	}
+	return "general"
+}
+
+// DateLayout returns the time layout used to show dates
+func (c *Config) DateLayout() string {
+	if c.DateFormat != "" {
+		return c.DateFormat
+	}
+	return DefaultDateFormat
+}
+
+// Columns returns the columns of 'pmt list'
+func (c *Config) Columns() []string {
+	if len(c.ListColumns) > 0 {
+		return c.ListColumns
+	}
+	return strings.Split(DefaultColumns, ",")
+}
+
+// SelectorPageSize returns how many prompts selectors show at once
+func (c *Config) SelectorPageSize() int {
+	if c.PageSize > 0 {
+		return c.PageSize
+	}
+	return DefaultPageSize
+}
+
+// UseColor reports whether selectors use colors
+func (c *Config) UseColor() bool {
+	switch c.Color {
+	case ColorOn:
+		return true
+	case ColorOff:
+		return false
+	}
+	return os.Getenv("NO_COLOR") == ""
+}
+
+// TagPolicy returns how tags are normalized when they are written
+func (c *Config) TagPolicy() models.TagPolicy {
+	return models.TagPolicy{Lowercase: c.TagCase != TagCasePreserve, Synonyms: c.TagSynonyms}
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+		wantErr bool
+	}{
+		{command: "vim", want: []string{"vim"}},
+		{command: "  code   --wait ", want: []string{"code", "--wait"}},
+		{command: `"/Applications/Sublime Text.app/bin/subl" -w`, want: []string{"/Applications/Sublime Text.app/bin/subl", "-w"}},
+		{command: `'/opt/my editor/bin/ed' --flag`, want: []string{"/opt/my editor/bin/ed", "--flag"}},
+		{command: `/opt/my\ editor/ed`, want: []string{"/opt/my editor/ed"}},
+		{command: `"C:\Program Files\Vim\gvim.exe" -f`, want: []string{`C:\Program Files\Vim\gvim.exe`, "-f"}},
+		{command: `"say \"hi\""`, want: []string{`say "hi"`}},
+		{command: `'it''s'`, want: []string{"its"}},
+		{command: `emacs -nw ""`, want: []string{"emacs", "-nw", ""}},
+		{command: `"unterminated`, wantErr: true},
+		{command: `'unterminated`, wantErr: true},
+		{command: `trailing\`, wantErr: true},
+		{command: "   ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := splitCommand(tt.command)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		editor string
+		env    string
+		visual string
+		want   []string
+	}{
+		{name: "setting is split", editor: `"/opt/my editor/ed" -w`, env: "nano", want: []string{"/opt/my editor/ed", "-w"}},
+		{name: "$EDITOR is one path", env: "/opt/my editor/ed", want: []string{"/opt/my editor/ed"}},
+		{name: "$VISUAL without $EDITOR", visual: "/usr/bin/code", want: []string{"/usr/bin/code"}},
+		{name: "vim by default", want: []string{"vim"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("EDITOR", tt.env)
+			t.Setenv("VISUAL", tt.visual)
+			got, err := (&Config{Editor: tt.editor}).EditorCommand()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("EditorCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Context describes a context within a project. Contexts exist implicitly on
+// the prompts that use them; a record adds metadata and lets a context exist
+// before it has any prompts.
+type Context struct {
+	Path        string    `yaml:"path"`    // e.g. "backend/api"
+	Project     string    `yaml:"project"` // ID of the project the context belongs to
+	Description string    `yaml:"description,omitempty"`
+	Type        string    `yaml:"type,omitempty"`     // default type of prompts pushed into the context
+	Tags        []string  `yaml:"tags,omitempty"`     // tags added to prompts pushed into the context
+	Archived    bool      `yaml:"archived,omitempty"` // no longer accepts new prompts
+	CreatedAt   time.Time `yaml:"created_at"`
+}
+
+// FindContext returns the record of a context in a project, or nil if there is none
+func (s *PromptStore) FindContext(project, path string) *Context {
+	for i := range s.Contexts {
+		if s.Contexts[i].Project == project && s.Contexts[i].Path == NormalizeContext(path) {
+			return &s.Contexts[i]
+		}
+	}
+	return nil
+}
+
+// NormalizeContext returns the canonical form of a context path: lowercase,
+// with whitespace around segments and empty segments removed.
+// Example: " Backend//API/ " -> "backend/api"
+func NormalizeContext(path string) string {
+	var parts []string
+	for _, part := range strings.Split(path, "/") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// InContext reports whether a context path is the given context or one of its
+// sub-contexts, comparing canonical forms.
+// Example: "backend/api" is in "Backend", "backend-v2" is not.
+func InContext(path, context string) bool {
+	path, context = NormalizeContext(path), NormalizeContext(context)
+	return path == context || strings.HasPrefix(path, context+"/")
+}
+
+// MoveContext returns the canonical form of path with the context prefix from
+// replaced by to, and whether path was in from at all. An empty to moves
+// sub-contexts to the top level.
+func MoveContext(path, from, to string) (string, bool) {
+	path, from, to = NormalizeContext(path), NormalizeContext(from), NormalizeContext(to)
+	if path == from {
+		return to, true
+	}
+	if !strings.HasPrefix(path, from+"/") {
+		return path, false
+	}
+	remainder := strings.TrimPrefix(path, from+"/")
+	if to == "" {
+		return remainder, true
+	}
+	return to + "/" + remainder, true
+}
@@ -0,0 +1,73 @@
+package models
+
+import "testing"
+
+func TestNormalizeContext(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", ""},
+		{"backend", "backend"},
+		{"Backend/API", "backend/api"},
+		{"/backend/api/", "backend/api"},
+		{"backend//api", "backend/api"},
+		{" backend / api ", "backend/api"},
+		{"/", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeContext(tt.path); got != tt.want {
+			t.Errorf("NormalizeContext(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestInContext(t *testing.T) {
+	tests := []struct {
+		path    string
+		context string
+		want    bool
+	}{
+		{"backend", "backend", true},
+		{"backend/api", "backend", true},
+		{"backend/api", "Backend/", true},
+		{"Backend/API/auth", "backend/api", true},
+		{"backend-v2", "backend", false},
+		{"backend", "backend/api", false},
+		{"frontend", "backend", false},
+		{"", "", true},
+		{"backend", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := InContext(tt.path, tt.context); got != tt.want {
+			t.Errorf("InContext(%q, %q) = %v, want %v", tt.path, tt.context, got, tt.want)
+		}
+	}
+}
+
+func TestMoveContext(t *testing.T) {
+	tests := []struct {
+		path      string
+		from      string
+		to        string
+		want      string
+		wantMoved bool
+	}{
+		{"backend", "backend", "server", "server", true},
+		{"backend/api/auth", "backend", "server", "server/api/auth", true},
+		{"Backend/API", "backend/api", "server/rest", "server/rest", true},
+		{"backend/api", "backend", "", "api", true},
+		{"backend", "backend", "", "", true},
+		{"backend-v2/api", "backend", "server", "backend-v2/api", false},
+		{"Frontend/App", "backend", "server", "frontend/app", false},
+	}
+
+	for _, tt := range tests {
+		got, moved := MoveContext(tt.path, tt.from, tt.to)
+		if got != tt.want || moved != tt.wantMoved {
+			t.Errorf("MoveContext(%q, %q, %q) = %q, %v, want %q, %v", tt.path, tt.from, tt.to, got, moved, tt.want, tt.wantMoved)
+		}
+	}
+}
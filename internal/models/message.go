@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Message roles supported in chat prompts
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single turn of a multi-message chat prompt
+type Message struct {
+	Role    string `yaml:"role" json:"role"` // system, user or assistant
+	Content string `yaml:"content" json:"content"`
+}
+
+// IsChat reports whether the prompt is made of chat turns
+func (p *Prompt) IsChat() bool {
+	return len(p.Messages) > 0
+}
+
+// Text returns the prompt's content, or the turns of a chat prompt in their
+// text form, for display, search and editing
+func (p *Prompt) Text() string {
+	if p.IsChat() {
+		return FormatMessages(p.Messages)
+	}
+	return p.Content
+}
+
+// ConvertChat moves the turns of a chat prompt saved by earlier versions,
+// which kept them in Content behind role markers, into Messages
+func (p *Prompt) ConvertChat() {
+	if !p.Chat {
+		return
+	}
+	p.Chat = false
+	if messages, err := ParseMessages(p.Content); err == nil && !p.IsChat() {
+		p.Messages, p.Content = messages, ""
+	}
+}
+
+// IsValidRole reports whether role is a supported message role
+func IsValidRole(role string) bool {
+	return role == RoleSystem || role == RoleUser || role == RoleAssistant
+}
+
+// FormatMessages renders messages as text, with each turn introduced by a
+// role marker line such as "[system]". ParseMessages reverses it as long as no
+// turn contains a marker line of its own outside a code fence.
+func FormatMessages(messages []Message) string {
+	parts := make([]string, 0, len(messages))
+	for _, m := range messages {
+		parts = append(parts, fmt.Sprintf("[%s]\n%s", m.Role, strings.TrimSpace(m.Content)))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// ParseMessages splits text into messages at role marker lines ("[system]",
+// "[user]", "[assistant]"). Text before the first marker belongs to a user turn,
+// and markers inside fenced code blocks are ignored.
+// Example: "[system]\nBe terse.\n[user]\nHi" -> system "Be terse.", user "Hi"
+func ParseMessages(text string) ([]Message, error) {
+	var messages []Message
+	role := RoleUser
+	var body []string
+	inFence := false
+
+	flush := func() {
+		content := strings.TrimSpace(strings.Join(body, "\n"))
+		if content != "" {
+			messages = append(messages, Message{Role: role, Content: content})
+		}
+		body = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+
+		if !inFence && strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			marker := strings.ToLower(strings.Trim(trimmed, "[]"))
+			if IsValidRole(marker) {
+				flush()
+				role = marker
+				continue
+			}
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("chat prompt has no messages")
+	}
+	return messages, nil
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []Message
+		wantErr bool
+	}{
+		{
+			name: "plain text is a user turn",
+			text: "Fix the bug.",
+			want: []Message{{Role: RoleUser, Content: "Fix the bug."}},
+		},
+		{
+			name: "system and user",
+			text: "[system]\nBe terse.\n[user]\nHi",
+			want: []Message{{Role: RoleSystem, Content: "Be terse."}, {Role: RoleUser, Content: "Hi"}},
+		},
+		{
+			name: "text before the first marker is a user turn",
+			text: "Hello\n[assistant]\nHi there\n[user]\nBye",
+			want: []Message{
+				{Role: RoleUser, Content: "Hello"},
+				{Role: RoleAssistant, Content: "Hi there"},
+				{Role: RoleUser, Content: "Bye"},
+			},
+		},
+		{
+			name: "markers are case-insensitive and may be indented",
+			text: "  [SYSTEM]  \nBe terse.\n[User]\nHi",
+			want: []Message{{Role: RoleSystem, Content: "Be terse."}, {Role: RoleUser, Content: "Hi"}},
+		},
+		{
+			name: "markers inside code fences are content",
+			text: "[user]\nParse this:\n```\n[system]\n```",
+			want: []Message{{Role: RoleUser, Content: "Parse this:\n```\n[system]\n```"}},
+		},
+		{
+			name: "unknown markers are content",
+			text: "[user]\n[note]\nHi",
+			want: []Message{{Role: RoleUser, Content: "[note]\nHi"}},
+		},
+		{
+			name: "empty turns are dropped",
+			text: "[system]\n\n[user]\nHi",
+			want: []Message{{Role: RoleUser, Content: "Hi"}},
+		},
+		{
+			name:    "no content",
+			text:    "[system]\n  \n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMessages(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseMessages(%q) = %v, want an error", tt.text, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMessages(%q): %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMessages(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMessagesRoundTrip(t *testing.T) {
+	messages := []Message{
+		{Role: RoleSystem, Content: "Be terse."},
+		{Role: RoleUser, Content: "Review:\n```\n[assistant]\n```"},
+		{Role: RoleAssistant, Content: "Looks good."},
+	}
+
+	text := FormatMessages(messages)
+	want := "[system]\nBe terse.\n\n[user]\nReview:\n```\n[assistant]\n```\n\n[assistant]\nLooks good."
+	if text != want {
+		t.Errorf("FormatMessages() = %q, want %q", text, want)
+	}
+
+	parsed, err := ParseMessages(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, messages) {
+		t.Errorf("ParseMessages(FormatMessages()) = %v, want %v", parsed, messages)
+	}
+}
+
+func TestConvertChat(t *testing.T) {
+	tests := []struct {
+		name         string
+		prompt       Prompt
+		wantContent  string
+		wantMessages []Message
+	}{
+		{
+			name:         "legacy chat prompt",
+			prompt:       Prompt{Chat: true, Content: "[system]\nBe terse.\n\n[user]\nHi"},
+			wantMessages: []Message{{Role: RoleSystem, Content: "Be terse."}, {Role: RoleUser, Content: "Hi"}},
+		},
+		{
+			name:        "plain prompt with marker text",
+			prompt:      Prompt{Content: "[system]\nnot a chat prompt"},
+			wantContent: "[system]\nnot a chat prompt",
+		},
+		{
+			name:         "already converted",
+			prompt:       Prompt{Messages: []Message{{Role: RoleUser, Content: "[assistant]"}}},
+			wantMessages: []Message{{Role: RoleUser, Content: "[assistant]"}},
+		},
+		{
+			name:   "legacy chat prompt without content",
+			prompt: Prompt{Chat: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.prompt
+			p.ConvertChat()
+			if p.Chat {
+				t.Error("Chat is still set")
+			}
+			if p.Content != tt.wantContent {
+				t.Errorf("Content = %q, want %q", p.Content, tt.wantContent)
+			}
+			if !reflect.DeepEqual(p.Messages, tt.wantMessages) {
+				t.Errorf("Messages = %v, want %v", p.Messages, tt.wantMessages)
+			}
+		})
+	}
+}
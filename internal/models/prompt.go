@@ -1,6 +1,7 @@
 package models
 
 import (
+	"sort"
 	"strings"
 	"time"
 )
@@ -11,48 +12,90 @@ type Prompt struct {
 	Name      string    `yaml:"name"`      // user-defined title/name for the prompt
 	Content   string    `yaml:"content"`
 	Type      string    `yaml:"type"`      // bugfix, feature, refactor, test, general
-	Project   string    `yaml:"project"`   // from git detection
+	Project   string    `yaml:"project"`   // from git detection: normalized origin URL or root commit
 	Context   string    `yaml:"context"`   // user-defined context within a project (supports hierarchical paths like "backend/api/auth")
 	Tags      []string  `yaml:"tags"`
 	CreatedAt time.Time `yaml:"created_at"`
+
+	Extends  string            `yaml:"extends,omitempty"`  // ID of the base prompt this one inherits from
+	Vars     map[string]string `yaml:"vars,omitempty"`     // values for {{var:name}} placeholders, overriding the base's
+	Variants map[string]string `yaml:"variants,omitempty"` // named alternative contents, e.g. "terse", "detailed" or one per model
+
+	Messages []Message `yaml:"messages,omitempty"` // chat turns; Content is empty for chat prompts
+	Chat     bool      `yaml:"chat,omitempty"`     // legacy: Content held the turns behind role markers; converted on load
+
+	Annotations []Annotation `yaml:"annotations,omitempty"` // responses saved by 'pmt run --save'
+
+	Global bool `yaml:"global,omitempty"` // shown in every project, not only the one it was saved in
+
+	ProjectName string `yaml:"project_name,omitempty"` // display name of the project, e.g. the repository folder
+
+	DeletedAt time.Time `yaml:"deleted_at,omitempty"` // when the prompt was moved to the trash
+
+	Branch   string `yaml:"branch,omitempty"`   // git branch checked out when the prompt was pushed
+	Commit   string `yaml:"commit,omitempty"`   // HEAD commit when the prompt was pushed
+	Archived bool   `yaml:"archived,omitempty"` // hidden from apply, pop and list, e.g. after its branch was merged
+}
+
+// Annotation is a note attached to a prompt, such as a saved model response
+type Annotation struct {
+	Runner    string    `yaml:"runner"` // command that produced the output
+	Output    string    `yaml:"output"`
+	CreatedAt time.Time `yaml:"created_at"`
 }
 
 // PromptStore represents the collection of all prompts
 type PromptStore struct {
 	Prompts []Prompt `yaml:"prompts"`
+	Trash   []Prompt `yaml:"trash,omitempty"` // deleted prompts that can still be restored
+
+	Contexts []Context    `yaml:"contexts,omitempty"` // context records with descriptions and defaults
+	Types    []PromptType `yaml:"types,omitempty"`    // type registry; DefaultTypes while empty
 }
 
-// GetContextParts returns the context split into hierarchical parts
+// VariantNames returns the prompt's variant names in sorted order
+func (p *Prompt) VariantNames() []string {
+	names := make([]string, 0, len(p.Variants))
+	for name := range p.Variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DisplayProject returns the project's display name, falling back to its ID
+func (p *Prompt) DisplayProject() string {
+	if p.ProjectName != "" {
+		return p.ProjectName
+	}
+	return p.Project
+}
+
+// InProject reports whether the prompt belongs to the project with the given
+// ID. Display names are not unique, so resolve them to an ID first.
+func (p *Prompt) InProject(project string) bool {
+	return strings.EqualFold(p.Project, project)
+}
+
+// GetContextParts returns the canonical context split into hierarchical parts
 // Example: "backend/api/auth" -> ["backend", "api", "auth"]
 func (p *Prompt) GetContextParts() []string {
-	if p.Context == "" {
+	context := NormalizeContext(p.Context)
+	if context == "" {
 		return []string{}
 	}
-	return strings.Split(p.Context, "/")
+	return strings.Split(context, "/")
 }
 
-// MatchesContextPrefix checks if the prompt's context matches the given prefix
-// Example: prompt.Context="backend/api/auth" matches prefix "backend" and "backend/api"
+// MatchesContextPrefix checks if the prompt's context matches the given prefix,
+// comparing canonical forms
+// Example: prompt.Context="backend/api/auth" matches prefix "backend" and "Backend/API"
 func (p *Prompt) MatchesContextPrefix(prefix string) bool {
-	if prefix == "" {
-		return p.Context == ""
-	}
-	if p.Context == "" {
-		return false
-	}
-	// Exact match
-	if p.Context == prefix {
-		return true
-	}
-	// Prefix match with path separator
-	return strings.HasPrefix(p.Context, prefix+"/")
+	return InContext(p.Context, prefix)
 }
 
 // GetContextDepth returns the depth of the context hierarchy
 // Example: "backend/api/auth" returns 3, "" returns 0
 func (p *Prompt) GetContextDepth() int {
-	if p.Context == "" {
-		return 0
-	}
 	return len(p.GetContextParts())
 }
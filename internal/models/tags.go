@@ -0,0 +1,122 @@
+package models
+
+import "strings"
+
+// TagPolicy says how tags are normalized when they are written and queried
+type TagPolicy struct {
+	Lowercase bool              // store tags in lowercase
+	Synonyms  map[string]string // tags replaced by another tag, e.g. "pg" -> "postgres"
+}
+
+// NormalizeTag returns the canonical form of a single tag. Tags are
+// hierarchical like contexts: whitespace around segments and empty segments
+// are removed, and by policy the tag is lowercased. A synonym is replaced by
+// its tag, keeping any sub-tags.
+// Example: with pg -> postgres, " PG//Replication " -> "postgres/replication"
+func (p TagPolicy) NormalizeTag(tag string) string {
+	tag = strings.Join(tagSegments(tag), "/")
+	if p.Lowercase {
+		tag = strings.ToLower(tag)
+	}
+	return p.resolve(tag)
+}
+
+// resolve replaces the longest synonym that tag is in by its target
+func (p TagPolicy) resolve(tag string) string {
+	resolved := tag
+	longest := -1
+	for synonym, target := range p.Synonyms {
+		if len(synonym) <= longest {
+			continue
+		}
+		target = strings.Join(tagSegments(target), "/")
+		if p.Lowercase {
+			target = strings.ToLower(target)
+		}
+		if moved, ok := MoveTag(tag, synonym, target); ok {
+			resolved = moved
+			longest = len(synonym)
+		}
+	}
+	return resolved
+}
+
+// NormalizeTags returns tags in canonical form, without empty tags and
+// without duplicates, which are compared case-insensitively. The first
+// spelling of a tag is kept.
+// Example: [" Go", "go", "", "API "] -> ["go", "api"]
+func (p TagPolicy) NormalizeTags(tags []string) []string {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = p.NormalizeTag(tag)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// InTag reports whether a tag is the given tag or one of its sub-tags, with
+// the same semantics as InContext: segments are compared case-insensitively.
+// Example: "lang/go" is in "lang", "language" is not.
+func InTag(tag, parent string) bool {
+	return InContext(tag, parent)
+}
+
+// MoveTag returns tag with the prefix from replaced by to, keeping the
+// spelling of any sub-tags, and whether tag was in from at all.
+// Example: MoveTag("lang/Go", "lang", "language") -> "language/Go", true
+func MoveTag(tag, from, to string) (string, bool) {
+	from = NormalizeContext(from)
+	if from == "" || !InTag(tag, from) {
+		return tag, false
+	}
+	parts := tagSegments(tag)
+	rest := strings.Join(parts[len(strings.Split(from, "/")):], "/")
+	if rest == "" {
+		return to, true
+	}
+	if to == "" {
+		return rest, true
+	}
+	return to + "/" + rest, true
+}
+
+// Matches reports whether any of tags is the filter tag or one of its
+// sub-tags, resolving synonyms on both sides
+func (p TagPolicy) Matches(tags []string, filter string) bool {
+	resolved := p.NormalizeTag(filter)
+	for _, tag := range tags {
+		if InTag(tag, filter) || InTag(p.NormalizeTag(tag), resolved) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag reports whether the prompt has a tag or one of its sub-tags,
+// compared case-insensitively
+// Example: a prompt tagged "lang/go" has "lang" and "lang/go", not "go"
+func (p *Prompt) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if InTag(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// tagSegments splits a tag into its trimmed, non-empty segments
+func tagSegments(tag string) []string {
+	var parts []string
+	for _, part := range strings.Split(tag, "/") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
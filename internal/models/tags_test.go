@@ -0,0 +1,124 @@
+package models
+
+import "testing"
+
+func TestNormalizeTag(t *testing.T) {
+	lower := TagPolicy{Lowercase: true, Synonyms: map[string]string{"pg": "postgres", "golang": "Lang/Go"}}
+	preserve := TagPolicy{Synonyms: map[string]string{"pg": "postgres"}}
+
+	tests := []struct {
+		name   string
+		policy TagPolicy
+		tag    string
+		want   string
+	}{
+		{"trims and lowercases", lower, " Go ", "go"},
+		{"removes empty segments", lower, "lang//go/", "lang/go"},
+		{"empty tag", lower, " / ", ""},
+		{"synonym", lower, "pg", "postgres"},
+		{"synonym keeps sub-tags", lower, " PG//Replication ", "postgres/replication"},
+		{"synonym target is normalized", lower, "golang", "lang/go"},
+		{"no synonym for a longer segment", lower, "pgbouncer", "pgbouncer"},
+		{"case is preserved", preserve, "Lang/ Go", "Lang/Go"},
+		{"synonyms match any case", preserve, "PG/Replication", "postgres/Replication"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.NormalizeTag(tt.tag); got != tt.want {
+				t.Errorf("NormalizeTag(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveLongestSynonym(t *testing.T) {
+	policy := TagPolicy{Lowercase: true, Synonyms: map[string]string{
+		"lang":    "language",
+		"lang/go": "golang",
+	}}
+
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"lang", "language"},
+		{"lang/rust", "language/rust"},
+		{"lang/go", "golang"},
+		{"lang/go/generics", "golang/generics"},
+		{"language", "language"},
+		{"other", "other"},
+	}
+
+	// Map iteration order is random, so resolve each tag several times
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if got := policy.resolve(tt.tag); got != tt.want {
+				t.Fatalf("resolve(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestMoveTag(t *testing.T) {
+	tests := []struct {
+		tag       string
+		from      string
+		to        string
+		want      string
+		wantMoved bool
+	}{
+		{"lang", "lang", "language", "language", true},
+		{"lang/Go", "lang", "language", "language/Go", true},
+		{"Lang/Go/generics", "lang/go", "golang", "golang/generics", true},
+		{"lang/go", "lang", "", "go", true},
+		{"language", "lang", "x", "language", false},
+		{"go", "lang", "language", "go", false},
+		{"lang", "", "x", "lang", false},
+	}
+
+	for _, tt := range tests {
+		got, moved := MoveTag(tt.tag, tt.from, tt.to)
+		if got != tt.want || moved != tt.wantMoved {
+			t.Errorf("MoveTag(%q, %q, %q) = %q, %v, want %q, %v", tt.tag, tt.from, tt.to, got, moved, tt.want, tt.wantMoved)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	policy := TagPolicy{Lowercase: true, Synonyms: map[string]string{"pg": "postgres"}}
+
+	tests := []struct {
+		name   string
+		tags   []string
+		filter string
+		want   bool
+	}{
+		{"exact tag", []string{"go"}, "go", true},
+		{"case-insensitive", []string{"go"}, "Go", true},
+		{"sub-tag", []string{"lang/go"}, "lang", true},
+		{"parent does not match a sub-tag filter", []string{"lang"}, "lang/go", false},
+		{"segment prefix is not a parent", []string{"language"}, "lang", false},
+		{"last segment alone does not match", []string{"lang/go"}, "go", false},
+		{"synonym in the filter", []string{"postgres/replication"}, "pg", true},
+		{"synonym in a stored tag", []string{"pg"}, "postgres", true},
+		{"no tags", nil, "go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Matches(tt.tags, tt.filter); got != tt.want {
+				t.Errorf("Matches(%q, %q) = %v, want %v", tt.tags, tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	policy := TagPolicy{Lowercase: true}
+	got := policy.NormalizeTags([]string{" Go", "go", "", "API ", "GO"})
+	want := []string{"go", "api"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("NormalizeTags() = %q, want %q", got, want)
+	}
+}
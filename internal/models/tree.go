@@ -0,0 +1,90 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// TreeNode represents a node in the context tree
+type TreeNode struct {
+	Name     string
+	Path     string // full context path, e.g. "backend/api"
+	Prompts  int    // prompts in this context and all of its sub-contexts
+	Direct   int    // prompts in exactly this context
+	Children map[string]*TreeNode
+}
+
+// BuildContextTree arranges the contexts of the given prompts into a tree.
+// It also returns the number of prompts without a context, which are not
+// part of the tree.
+func BuildContextTree(prompts []Prompt) (*TreeNode, int) {
+	root := &TreeNode{
+		Name:     "",
+		Children: make(map[string]*TreeNode),
+	}
+
+	// Count prompts without context
+	noContextCount := 0
+
+	for _, p := range prompts {
+		parts := p.GetContextParts()
+		if len(parts) == 0 {
+			noContextCount++
+			continue
+		}
+
+		current := root.walk(parts, true)
+		current.Direct++
+	}
+
+	return root, noContextCount
+}
+
+// AddContext adds a context to the tree without counting any prompts, so
+// contexts that exist only as records still show up
+func (n *TreeNode) AddContext(path string) {
+	path = NormalizeContext(path)
+	if path == "" {
+		return
+	}
+	n.walk(strings.Split(path, "/"), false)
+}
+
+// walk returns the node at the given path below n, creating missing nodes
+// and counting a prompt on every node passed when count is set
+func (n *TreeNode) walk(parts []string, count bool) *TreeNode {
+	current := n
+	for _, part := range parts {
+		if current.Children[part] == nil {
+			path := part
+			if current.Path != "" {
+				path = current.Path + "/" + part
+			}
+			current.Children[part] = &TreeNode{
+				Name:     part,
+				Path:     path,
+				Children: make(map[string]*TreeNode),
+			}
+		}
+		current = current.Children[part]
+		if count {
+			current.Prompts++
+		}
+	}
+	return current
+}
+
+// SortedChildren returns the children of a node sorted by name
+func (n *TreeNode) SortedChildren() []*TreeNode {
+	names := make([]string, 0, len(n.Children))
+	for name := range n.Children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	children := make([]*TreeNode, len(names))
+	for i, name := range names {
+		children[i] = n.Children[name]
+	}
+	return children
+}
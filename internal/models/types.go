@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PromptType is an entry of the type registry. Prompts refer to it by name.
+type PromptType struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Color       string `yaml:"color,omitempty"`    // color of the type in selectors, one of TypeColors
+	Template    string `yaml:"template,omitempty"` // initial content of the editor when pushing a prompt of this type
+}
+
+// DefaultTypes make up the registry until types are added, renamed or removed
+var DefaultTypes = []PromptType{
+	{Name: "bugfix", Description: "Fixing a bug", Color: "red"},
+	{Name: "feature", Description: "Building a new feature", Color: "green"},
+	{Name: "refactor", Description: "Restructuring existing code", Color: "blue"},
+	{Name: "test", Description: "Writing or fixing tests", Color: "magenta"},
+	{Name: "general", Description: "Anything else", Color: "yellow"},
+}
+
+// TypeColors are the colors a type can be shown in
+var TypeColors = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+var typeName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// NormalizeTypeName returns the canonical, lowercase form of a type name and
+// checks that it consists of letters, digits, - and _
+func NormalizeTypeName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !typeName.MatchString(name) {
+		return "", fmt.Errorf("invalid type name: %q (use letters, digits, - and _)", name)
+	}
+	return name, nil
+}
+
+// ValidateTypeColor checks that a color is empty or one of TypeColors
+func ValidateTypeColor(color string) error {
+	if color == "" {
+		return nil
+	}
+	for _, c := range TypeColors {
+		if c == color {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid color: %s (must be one of %s)", color, strings.Join(TypeColors, ", "))
+}
+
+// TypeRegistry returns the registered types: those stored with the prompts,
+// or DefaultTypes while none are stored
+func (s *PromptStore) TypeRegistry() []PromptType {
+	if len(s.Types) > 0 {
+		return s.Types
+	}
+	return append([]PromptType(nil), DefaultTypes...)
+}
+
+// FindType returns the registered type with the given name, or nil. Changes
+// to it are only kept once the registry is stored, see StoreTypes.
+func (s *PromptStore) FindType(name string) *PromptType {
+	registry := s.TypeRegistry()
+	for i := range registry {
+		if strings.EqualFold(registry[i].Name, strings.TrimSpace(name)) {
+			return &registry[i]
+		}
+	}
+	return nil
+}
+
+// StoreTypes stores the default types with the prompts, so that the registry
+// can be changed
+func (s *PromptStore) StoreTypes() {
+	if len(s.Types) == 0 {
+		s.Types = s.TypeRegistry()
+	}
+}
+
+// TypeNames returns the names of the registered types in registry order
+func (s *PromptStore) TypeNames() []string {
+	registry := s.TypeRegistry()
+	names := make([]string, len(registry))
+	for i, t := range registry {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// ValidateType checks that a type is registered and returns its registered name
+func (s *PromptStore) ValidateType(name string) (string, error) {
+	t := s.FindType(name)
+	if t == nil {
+		return "", fmt.Errorf("invalid type: %s (must be one of %s; see 'pmt type list')", name, strings.Join(s.TypeNames(), ", "))
+	}
+	return t.Name, nil
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+// Output formats for rendered prompts
+const (
+	FormatPlain         = "plain"
+	FormatOpenAIJSON    = "openai-json"
+	FormatAnthropicJSON = "anthropic-json"
+)
+
+// DefaultMaxTokens is used for the Anthropic request body, where max_tokens is required
+const DefaultMaxTokens = 1024
+
+// ChatOptions holds request fields that are not part of the prompt itself
+type ChatOptions struct {
+	Model     string
+	MaxTokens int
+}
+
+type openAIRequest struct {
+	Model    string           `json:"model,omitempty"`
+	Messages []models.Message `json:"messages"`
+}
+
+type anthropicRequest struct {
+	Model     string           `json:"model,omitempty"`
+	MaxTokens int              `json:"max_tokens"`
+	System    string           `json:"system,omitempty"`
+	Messages  []models.Message `json:"messages"`
+}
+
+// FormatChat formats messages as plain text or as a provider's chat request body
+func FormatChat(messages []models.Message, format string, opts ChatOptions) (string, error) {
+	switch format {
+	case "", FormatPlain:
+		if len(messages) == 1 && messages[0].Role == models.RoleUser {
+			return messages[0].Content, nil
+		}
+		return models.FormatMessages(messages), nil
+
+	case FormatOpenAIJSON:
+		return marshalRequest(openAIRequest{Model: opts.Model, Messages: messages})
+
+	case FormatAnthropicJSON:
+		// Anthropic takes the system prompt as a top-level field and expects
+		// alternating user/assistant turns, so consecutive turns are merged
+		var system []string
+		var turns []models.Message
+		for _, m := range messages {
+			if m.Role == models.RoleSystem {
+				system = append(system, m.Content)
+				continue
+			}
+			if n := len(turns); n > 0 && turns[n-1].Role == m.Role {
+				turns[n-1].Content += "\n\n" + m.Content
+				continue
+			}
+			turns = append(turns, m)
+		}
+		if len(turns) == 0 || turns[0].Role != models.RoleUser {
+			return "", fmt.Errorf("anthropic requests must start with a user message")
+		}
+
+		maxTokens := opts.MaxTokens
+		if maxTokens <= 0 {
+			maxTokens = DefaultMaxTokens
+		}
+		return marshalRequest(anthropicRequest{
+			Model:     opts.Model,
+			MaxTokens: maxTokens,
+			System:    strings.Join(system, "\n\n"),
+			Messages:  turns,
+		})
+	}
+
+	return "", fmt.Errorf("invalid format: %s (must be %s, %s, or %s)", format, FormatPlain, FormatOpenAIJSON, FormatAnthropicJSON)
+}
+
+func marshalRequest(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,170 @@
+package render
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+func TestFormatChatPlain(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []models.Message
+		want     string
+	}{
+		{
+			name:     "single user turn is copied as is",
+			messages: []models.Message{{Role: models.RoleUser, Content: "Fix it."}},
+			want:     "Fix it.",
+		},
+		{
+			name: "several turns keep their markers",
+			messages: []models.Message{
+				{Role: models.RoleSystem, Content: "Be terse."},
+				{Role: models.RoleUser, Content: "Fix it."},
+			},
+			want: "[system]\nBe terse.\n\n[user]\nFix it.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatChat(tt.messages, FormatPlain, ChatOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("FormatChat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatChatOpenAI(t *testing.T) {
+	messages := []models.Message{
+		{Role: models.RoleSystem, Content: "Be terse."},
+		{Role: models.RoleUser, Content: "Fix it."},
+	}
+	got, err := FormatChat(messages, FormatOpenAIJSON, ChatOptions{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var req openAIRequest
+	if err := json.Unmarshal([]byte(got), &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Model != "gpt-4o" || !reflect.DeepEqual(req.Messages, messages) {
+		t.Errorf("FormatChat() = %s", got)
+	}
+}
+
+func TestFormatChatAnthropic(t *testing.T) {
+	tests := []struct {
+		name      string
+		messages  []models.Message
+		maxTokens int
+		want      anthropicRequest
+		wantErr   bool
+	}{
+		{
+			name: "system turns move to the system field",
+			messages: []models.Message{
+				{Role: models.RoleSystem, Content: "Be terse."},
+				{Role: models.RoleUser, Content: "Fix it."},
+				{Role: models.RoleSystem, Content: "Use Go."},
+			},
+			want: anthropicRequest{
+				MaxTokens: DefaultMaxTokens,
+				System:    "Be terse.\n\nUse Go.",
+				Messages:  []models.Message{{Role: models.RoleUser, Content: "Fix it."}},
+			},
+		},
+		{
+			name: "consecutive turns of one role are merged",
+			messages: []models.Message{
+				{Role: models.RoleUser, Content: "One."},
+				{Role: models.RoleUser, Content: "Two."},
+				{Role: models.RoleAssistant, Content: "Three."},
+			},
+			maxTokens: 2048,
+			want: anthropicRequest{
+				MaxTokens: 2048,
+				Messages: []models.Message{
+					{Role: models.RoleUser, Content: "One.\n\nTwo."},
+					{Role: models.RoleAssistant, Content: "Three."},
+				},
+			},
+		},
+		{
+			name:     "only system turns",
+			messages: []models.Message{{Role: models.RoleSystem, Content: "Be terse."}},
+			wantErr:  true,
+		},
+		{
+			name: "first turn is the assistant's",
+			messages: []models.Message{
+				{Role: models.RoleSystem, Content: "Be terse."},
+				{Role: models.RoleAssistant, Content: "Hi."},
+				{Role: models.RoleUser, Content: "Fix it."},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatChat(tt.messages, FormatAnthropicJSON, ChatOptions{MaxTokens: tt.maxTokens})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("FormatChat() = %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var req anthropicRequest
+			if err := json.Unmarshal([]byte(got), &req); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(req, tt.want) {
+				t.Errorf("FormatChat() = %+v, want %+v", req, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatChatInvalidFormat(t *testing.T) {
+	if _, err := FormatChat(nil, "xml", ChatOptions{}); err == nil {
+		t.Error("FormatChat with an unknown format did not fail")
+	}
+}
+
+func TestRenderMessagesKeepsIncludedMarkers(t *testing.T) {
+	partial := &models.Prompt{ID: "p1", Name: "notes", Content: "Notes:\n[user]\nnot a turn"}
+	chat := &models.Prompt{
+		ID: "c1",
+		Messages: []models.Message{
+			{Role: models.RoleSystem, Content: "Be terse."},
+			{Role: models.RoleUser, Content: "{{> notes}}\n{{var:extra}}\n[system]\nstored text"},
+		},
+		Vars: map[string]string{"extra": "[assistant]\nstill the user"},
+	}
+
+	r := &Renderer{Root: t.TempDir(), Lookup: func(ref string) (*models.Prompt, error) { return partial, nil }}
+	got, err := r.RenderMessages(chat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.Message{
+		{Role: models.RoleSystem, Content: "Be terse."},
+		{Role: models.RoleUser, Content: "Notes:\n[user]\nnot a turn\n[assistant]\nstill the user\n[system]\nstored text"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RenderMessages() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+// sectionPattern matches a named, overridable block: {{section:name}}...{{/section}}
+var sectionPattern = regexp.MustCompile(`(?s)\{\{\s*section:([\w.-]+)\s*\}\}(.*?)\{\{\s*/section\s*\}\}`)
+
+// varPattern matches a variable reference: {{var:name}}
+var varPattern = regexp.MustCompile(`\{\{\s*var:([\w.-]+)\s*\}\}`)
+
+// resolve returns the content and variables of a prompt after applying its
+// extends chain and the given variant; partials are resolved without one.
+// chain holds the IDs already visited and is used for cycle detection.
+func (r *Renderer) resolve(p *models.Prompt, chain []string, variant string) (string, map[string]string, error) {
+	for _, id := range chain {
+		if id == p.ID {
+			cycle := append(append([]string{}, chain...), p.ID)
+			return "", nil, fmt.Errorf("extends cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	chain = append(chain, p.ID)
+
+	// A chat prompt included as a partial or base contributes its text form
+	own := p.Text()
+	if variant != "" {
+		if content, ok := p.Variants[variant]; ok {
+			own = content
+			r.variantFound = true
+		}
+	}
+
+	if p.Extends == "" {
+		return own, copyVars(p.Vars), nil
+	}
+
+	if r.Lookup == nil {
+		return "", nil, fmt.Errorf("prompt %s extends %s, but no prompt lookup is available", p.ID, p.Extends)
+	}
+	base, err := r.Lookup(p.Extends)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve base prompt %s of %s: %w", p.Extends, p.ID, err)
+	}
+
+	content, vars, err := r.resolve(base, chain, variant)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Sections defined by the child replace the base's sections of the same name
+	overrides := make(map[string]string)
+	for _, m := range sectionPattern.FindAllStringSubmatch(own, -1) {
+		overrides[m[1]] = m[2]
+	}
+	content = sectionPattern.ReplaceAllStringFunc(content, func(match string) string {
+		m := sectionPattern.FindStringSubmatch(match)
+		if body, ok := overrides[m[1]]; ok {
+			delete(overrides, m[1])
+			return "{{section:" + m[1] + "}}" + body + "{{/section}}"
+		}
+		return match
+	})
+	if len(overrides) > 0 {
+		names := make([]string, 0, len(overrides))
+		for name := range overrides {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", nil, fmt.Errorf("prompt %s overrides unknown section(s) of %s: %s", p.ID, base.ID, strings.Join(names, ", "))
+	}
+
+	// Anything the child writes outside of sections is appended to the base
+	if extra := strings.TrimSpace(sectionPattern.ReplaceAllString(own, "")); extra != "" {
+		content = strings.TrimRight(content, "\n") + "\n\n" + extra
+	}
+
+	for k, v := range p.Vars {
+		vars[k] = v
+	}
+
+	return content, vars, nil
+}
+
+// VariantSource returns the prompt of p's extends chain that defines the
+// named variant, starting with p itself, or nil if none does
+func (r *Renderer) VariantSource(p *models.Prompt, variant string) (*models.Prompt, error) {
+	var chain []string
+	for {
+		if _, ok := p.Variants[variant]; ok {
+			return p, nil
+		}
+		chain = append(chain, p.ID)
+		if p.Extends == "" || r.Lookup == nil {
+			return nil, nil
+		}
+		base, err := r.Lookup(p.Extends)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve base prompt %s of %s: %w", p.Extends, p.ID, err)
+		}
+		for _, id := range chain {
+			if id == base.ID {
+				return nil, fmt.Errorf("extends cycle detected: %s", strings.Join(append(chain, base.ID), " -> "))
+			}
+		}
+		p = base
+	}
+}
+
+// stripSections removes section markers, keeping their bodies
+func stripSections(content string) string {
+	return sectionPattern.ReplaceAllString(content, "$2")
+}
+
+// substituteVars replaces {{var:name}} references, failing on undefined variables
+func substituteVars(content string, vars map[string]string) (string, error) {
+	var missing []string
+	result := varPattern.ReplaceAllStringFunc(content, func(match string) string {
+		name := varPattern.FindStringSubmatch(match)[1]
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variable(s): %s (set them with --var name=value)", strings.Join(missing, ", "))
+	}
+	return result, nil
+}
+
+func copyVars(vars map[string]string) map[string]string {
+	copied := make(map[string]string, len(vars))
+	for k, v := range vars {
+		copied[k] = v
+	}
+	return copied
+}
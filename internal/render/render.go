@@ -0,0 +1,529 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/utils"
+)
+
+// DefaultMaxBytes is the default total size budget for included files
+const DefaultMaxBytes = 256 * 1024
+
+// fileRefPattern matches {{file:path}}, {{file:glob/**/*.go}} and {{file:path#L10-40}}
+var fileRefPattern = regexp.MustCompile(`\{\{\s*file:([^}]+?)\s*\}\}`)
+
+// partialPattern matches {{> name-or-id}} references to other prompts
+var partialPattern = regexp.MustCompile(`\{\{>\s*([^}]+?)\s*\}\}`)
+
+// lineRangePattern matches the "#L10-40" or "#L10" suffix of a file reference
+var lineRangePattern = regexp.MustCompile(`#L(\d+)(?:-L?(\d+))?$`)
+
+// Renderer expands placeholders in prompt content at apply time
+type Renderer struct {
+	Root     string // directory file references are resolved against (usually the git toplevel)
+	MaxBytes int    // total byte budget for files included by everything rendered; 0 means DefaultMaxBytes
+
+	// Lookup resolves a {{> ref}} partial or an extends reference to a prompt by ID or name.
+	// Partials are left untouched when Lookup is nil.
+	Lookup func(ref string) (*models.Prompt, error)
+
+	Variant string            // named variant of the rendered prompt and its bases; partials use their default content
+	Vars    map[string]string // variable overrides, taking precedence over the prompt's own vars
+
+	used         int               // bytes included so far
+	partialVars  map[string]string // vars defined by included partials, used as defaults
+	variantFound bool              // whether Variant exists in the rendered prompt's extends chain
+}
+
+// NewRenderer creates a Renderer rooted at the current git toplevel,
+// or at the working directory when not inside a git repository
+func NewRenderer() (*Renderer, error) {
+	root, err := utils.GitRoot()
+	if err != nil {
+		root, err = os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+	}
+	return &Renderer{Root: root, MaxBytes: DefaultMaxBytes}, nil
+}
+
+// Render expands all placeholders in content.
+// Partials are resolved recursively first, so included prompts may use file placeholders too.
+func (r *Renderer) Render(content string) (string, error) {
+	expanded, err := r.expandPartials(content, nil)
+	if err != nil {
+		return "", err
+	}
+	return r.expandFiles(expanded)
+}
+
+// RenderPrompt renders a prompt: it applies the extends chain and selected variant,
+// expands partials, substitutes variables and finally expands file placeholders.
+// Unlike Render, a partial that includes the prompt itself is reported as a cycle.
+// Chat prompts are rendered turn by turn, see RenderMessages.
+func (r *Renderer) RenderPrompt(p *models.Prompt) (string, error) {
+	if p.IsChat() {
+		messages, err := r.RenderMessages(p)
+		if err != nil {
+			return "", err
+		}
+		return models.FormatMessages(messages), nil
+	}
+
+	content, vars, err := r.source(p)
+	if err != nil {
+		return "", err
+	}
+	expanded, err := r.expand(p, []string{content}, vars)
+	if err != nil {
+		return "", err
+	}
+	return expanded[0], nil
+}
+
+// RenderMessages renders a prompt into chat turns. The stored turns of a chat
+// prompt are rendered one by one, so text that comes from partials, variables
+// or files never starts a new turn. Any other prompt is a single user turn.
+// Chat prompts have no extends chain or variants.
+func (r *Renderer) RenderMessages(p *models.Prompt) ([]models.Message, error) {
+	if !p.IsChat() {
+		rendered, err := r.RenderPrompt(p)
+		if err != nil {
+			return nil, err
+		}
+		return []models.Message{{Role: models.RoleUser, Content: rendered}}, nil
+	}
+
+	if p.Extends != "" {
+		return nil, fmt.Errorf("chat prompt %s cannot extend %s", p.ID, p.Extends)
+	}
+	if r.Variant != "" {
+		return nil, fmt.Errorf("prompt %s has no variant %q", p.ID, r.Variant)
+	}
+
+	messages := append([]models.Message(nil), p.Messages...)
+	bodies := make([]string, len(messages))
+	for i, m := range messages {
+		bodies[i] = m.Content
+	}
+	expanded, err := r.expand(p, bodies, copyVars(p.Vars))
+	if err != nil {
+		return nil, err
+	}
+	for i := range messages {
+		messages[i].Content = strings.TrimSpace(expanded[i])
+	}
+	return messages, nil
+}
+
+// source returns a prompt's unexpanded content and variables after applying
+// its extends chain and the selected variant
+func (r *Renderer) source(p *models.Prompt) (string, map[string]string, error) {
+	r.variantFound = false
+	content, vars, err := r.resolve(p, nil, r.Variant)
+	if err != nil {
+		return "", nil, err
+	}
+	if r.Variant != "" && !r.variantFound {
+		return "", nil, fmt.Errorf("prompt %s has no variant %q", p.ID, r.Variant)
+	}
+	return content, vars, nil
+}
+
+// expand expands partials, variables and file placeholders in the bodies of
+// prompt p, which defines vars. Variables defined by the partials of any body
+// are defaults for all of them.
+func (r *Renderer) expand(p *models.Prompt, bodies []string, vars map[string]string) ([]string, error) {
+	r.partialVars = make(map[string]string)
+	expanded := make([]string, len(bodies))
+	for i, body := range bodies {
+		var err error
+		expanded[i], err = r.expandPartials(body, []string{p.ID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Precedence: renderer overrides > prompt (and its bases) > partials
+	merged := copyVars(r.partialVars)
+	for k, v := range vars {
+		merged[k] = v
+	}
+	for k, v := range r.Vars {
+		merged[k] = v
+	}
+
+	for i := range expanded {
+		body, err := substituteVars(stripSections(expanded[i]), merged)
+		if err != nil {
+			return nil, err
+		}
+		expanded[i], err = r.expandFiles(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return expanded, nil
+}
+
+// expandPartials replaces {{> ref}} with the content of the referenced prompt.
+// stack holds the IDs currently being expanded and is used for cycle detection.
+func (r *Renderer) expandPartials(content string, stack []string) (string, error) {
+	if r.Lookup == nil {
+		return content, nil
+	}
+
+	var renderErr error
+	result := partialPattern.ReplaceAllStringFunc(content, func(match string) string {
+		if renderErr != nil {
+			return match
+		}
+		ref := partialPattern.FindStringSubmatch(match)[1]
+
+		partial, err := r.Lookup(ref)
+		if err != nil {
+			renderErr = fmt.Errorf("failed to resolve partial {{> %s}}: %w", ref, err)
+			return match
+		}
+
+		for _, id := range stack {
+			if id == partial.ID {
+				chain := append(append([]string{}, stack...), partial.ID)
+				renderErr = fmt.Errorf("include cycle detected: %s", strings.Join(chain, " -> "))
+				return match
+			}
+		}
+
+		content, vars, err := r.resolve(partial, nil, "")
+		if err != nil {
+			renderErr = err
+			return match
+		}
+		for k, v := range vars {
+			if _, ok := r.partialVars[k]; !ok && r.partialVars != nil {
+				r.partialVars[k] = v
+			}
+		}
+
+		expanded, err := r.expandPartials(content, append(stack, partial.ID))
+		if err != nil {
+			renderErr = err
+			return match
+		}
+		return expanded
+	})
+	if renderErr != nil {
+		return "", renderErr
+	}
+	return result, nil
+}
+
+// expandFiles replaces {{file:...}} references with fenced code blocks
+func (r *Renderer) expandFiles(content string) (string, error) {
+	var renderErr error
+	result := fileRefPattern.ReplaceAllStringFunc(content, func(match string) string {
+		if renderErr != nil {
+			return match
+		}
+		spec := fileRefPattern.FindStringSubmatch(match)[1]
+		expanded, err := r.expandFileRef(spec)
+		if err != nil {
+			renderErr = err
+			return match
+		}
+		return expanded
+	})
+	if renderErr != nil {
+		return "", renderErr
+	}
+	return result, nil
+}
+
+// expandFileRef turns a single file reference into one or more fenced code blocks
+func (r *Renderer) expandFileRef(spec string) (string, error) {
+	spec = strings.TrimSpace(spec)
+
+	// Split off an optional line range
+	startLine, endLine := 0, 0
+	if m := lineRangePattern.FindStringSubmatch(spec); m != nil {
+		startLine, _ = strconv.Atoi(m[1])
+		endLine = startLine
+		if m[2] != "" {
+			endLine, _ = strconv.Atoi(m[2])
+		}
+		if startLine < 1 || endLine < startLine {
+			return "", fmt.Errorf("invalid line range in {{file:%s}}", spec)
+		}
+		spec = strings.TrimSuffix(spec, m[0])
+	}
+
+	pattern := filepath.ToSlash(filepath.Clean(spec))
+	if filepath.IsAbs(spec) || pattern == ".." || strings.HasPrefix(pattern, "../") {
+		return "", fmt.Errorf("file reference %s must be relative to the repository root", spec)
+	}
+
+	var files []string
+	if isGlob(pattern) {
+		if startLine > 0 {
+			return "", fmt.Errorf("line ranges cannot be combined with globs: {{file:%s}}", spec)
+		}
+		matches, err := r.globFiles(pattern)
+		if err != nil {
+			return "", err
+		}
+		if len(matches) == 0 {
+			return "", fmt.Errorf("no files match {{file:%s}}", spec)
+		}
+		files = matches
+	} else {
+		// Globs only match files git does not ignore; neither may explicit paths
+		if utils.GitIgnored(r.Root, pattern) {
+			return "", fmt.Errorf("included file %s is ignored by git", pattern)
+		}
+		files = []string{pattern}
+	}
+
+	blocks := make([]string, 0, len(files))
+	for _, f := range files {
+		block, err := r.fileBlock(f, startLine, endLine, len(files) > 1)
+		if err != nil {
+			return "", err
+		}
+		if block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+// fileBlock reads a file (or a line range of it) and formats it as a fenced code block.
+// Binary files return an empty block when skipBinary is set, and an error otherwise.
+func (r *Renderer) fileBlock(relPath string, startLine, endLine int, skipBinary bool) (string, error) {
+	path, err := r.resolvePath(relPath)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read included file %s: %w", relPath, err)
+	}
+
+	if bytes.IndexByte(data, 0) >= 0 {
+		if skipBinary {
+			return "", nil
+		}
+		return "", fmt.Errorf("included file %s is binary", relPath)
+	}
+
+	text := string(data)
+	label := relPath
+	if startLine > 0 {
+		lines := strings.SplitAfter(text, "\n")
+		// A final newline ends the last line rather than starting another
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		if startLine > len(lines) {
+			return "", fmt.Errorf("line range L%d-%d is outside %s (%d lines)", startLine, endLine, relPath, len(lines))
+		}
+		if endLine > len(lines) {
+			endLine = len(lines)
+		}
+		text = strings.Join(lines[startLine-1:endLine], "")
+		label = fmt.Sprintf("%s (lines %d-%d)", relPath, startLine, endLine)
+	}
+
+	r.used += len(text)
+	if r.used > r.maxBytes() {
+		return "", fmt.Errorf("include budget of %d bytes exceeded while adding %s", r.maxBytes(), relPath)
+	}
+
+	// Use a fence longer than any backtick run inside the file
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+
+	return fmt.Sprintf("%s\n%s%s\n%s\n%s", label, fence, languageFor(relPath), strings.TrimRight(text, "\n"), fence), nil
+}
+
+// resolvePath returns the absolute path of a file under Root, following
+// symlinks only as long as they stay inside Root
+func (r *Renderer) resolvePath(relPath string) (string, error) {
+	root, err := filepath.EvalSymlinks(r.Root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve repository root: %w", err)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(r.Root, filepath.FromSlash(relPath)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read included file %s: %w", relPath, err)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("included file %s is outside the repository", relPath)
+	}
+	return path, nil
+}
+
+// globFiles returns the files under Root that match pattern, honouring .gitignore
+func (r *Renderer) globFiles(pattern string) ([]string, error) {
+	candidates, err := utils.GitListFiles(r.Root)
+	if err != nil {
+		// Not a git repository: walk the tree instead
+		candidates = nil
+		walkErr := filepath.WalkDir(r.Root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel, err := filepath.Rel(r.Root, path)
+			if err != nil {
+				return err
+			}
+			candidates = append(candidates, filepath.ToSlash(rel))
+			return nil
+		})
+		if walkErr != nil {
+			return nil, fmt.Errorf("failed to list files: %w", walkErr)
+		}
+	}
+
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, f := range candidates {
+		if !re.MatchString(f) {
+			continue
+		}
+		// git ls-files --cached also reports files deleted from the working tree
+		if info, err := os.Stat(filepath.Join(r.Root, filepath.FromSlash(f))); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		matches = append(matches, f)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func (r *Renderer) maxBytes() int {
+	if r.MaxBytes <= 0 {
+		return DefaultMaxBytes
+	}
+	return r.MaxBytes
+}
+
+// isGlob reports whether a path contains glob metacharacters
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// globToRegexp converts a slash-separated glob into a regular expression.
+// "*" and "?" never cross a "/", while "**" matches any number of directories.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more leading directories
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid glob %q: unclosed '['", pattern)
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// languageFor returns the code fence language hint for a file name
+func languageFor(path string) string {
+	base := strings.ToLower(filepath.Base(path))
+	switch base {
+	case "makefile":
+		return "makefile"
+	case "dockerfile":
+		return "dockerfile"
+	}
+
+	languages := map[string]string{
+		".go":    "go",
+		".mod":   "go",
+		".py":    "python",
+		".js":    "javascript",
+		".jsx":   "jsx",
+		".ts":    "typescript",
+		".tsx":   "tsx",
+		".rs":    "rust",
+		".rb":    "ruby",
+		".java":  "java",
+		".kt":    "kotlin",
+		".c":     "c",
+		".h":     "c",
+		".cc":    "cpp",
+		".cpp":   "cpp",
+		".hpp":   "cpp",
+		".cs":    "csharp",
+		".swift": "swift",
+		".php":   "php",
+		".sh":    "bash",
+		".bash":  "bash",
+		".zsh":   "zsh",
+		".sql":   "sql",
+		".html":  "html",
+		".css":   "css",
+		".scss":  "scss",
+		".json":  "json",
+		".yaml":  "yaml",
+		".yml":   "yaml",
+		".toml":  "toml",
+		".xml":   "xml",
+		".md":    "markdown",
+		".proto": "protobuf",
+		".tf":    "hcl",
+		".lua":   "lua",
+	}
+	return languages[filepath.Ext(base)]
+}
@@ -0,0 +1,117 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"cmd/*.go", "cmd/main.go", true},
+		{"cmd/*.go", "cmd/sub/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/sub/main.go", true},
+		{"internal/**/*.go", "internal/render/render.go", true},
+		{"internal/**/*.go", "internal/render.go", true},
+		{"internal/**/*.go", "cmd/render.go", false},
+		{"docs/**", "docs/a/b.md", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"file?.txt", "file/.txt", false},
+		{"[ab].txt", "a.txt", true},
+		{"[ab].txt", "c.txt", false},
+		{"[!ab].txt", "c.txt", true},
+		{"[!ab].txt", "a.txt", false},
+		{"a.b", "axb", false},
+		{"(x)+.md", "(x)+.md", true},
+	}
+
+	for _, tt := range tests {
+		re, err := globToRegexp(tt.pattern)
+		if err != nil {
+			t.Fatalf("globToRegexp(%q): %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("globToRegexp(%q) matches %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGlobToRegexpUnclosedClass(t *testing.T) {
+	if _, err := globToRegexp("file[ab.txt"); err == nil {
+		t.Error("globToRegexp with an unclosed '[' did not fail")
+	}
+}
+
+func TestExpandFileRefLineRanges(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "three.txt"), []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "open.txt"), []byte("one\ntwo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec    string
+		want    string // content between the fences
+		label   string
+		wantErr bool
+	}{
+		{spec: "three.txt", want: "one\ntwo\nthree", label: "three.txt"},
+		{spec: "three.txt#L2", want: "two", label: "three.txt (lines 2-2)"},
+		{spec: "three.txt#L2-3", want: "two\nthree", label: "three.txt (lines 2-3)"},
+		{spec: "three.txt#L2-L3", want: "two\nthree", label: "three.txt (lines 2-3)"},
+		{spec: "three.txt#L3-10", want: "three", label: "three.txt (lines 3-3)"},
+		{spec: "three.txt#L4", wantErr: true},
+		{spec: "three.txt#L0", wantErr: true},
+		{spec: "three.txt#L3-2", wantErr: true},
+		{spec: "open.txt#L2", want: "two", label: "open.txt (lines 2-2)"},
+		{spec: "open.txt#L3", wantErr: true},
+		{spec: "*.txt#L1", wantErr: true},
+		{spec: "../three.txt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		r := &Renderer{Root: root}
+		got, err := r.expandFileRef(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expandFileRef(%q) = %q, want an error", tt.spec, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expandFileRef(%q): %v", tt.spec, err)
+			continue
+		}
+		want := tt.label + "\n```\n" + tt.want + "\n```"
+		if got != want {
+			t.Errorf("expandFileRef(%q) = %q, want %q", tt.spec, got, want)
+		}
+	}
+}
+
+func TestExpandFileRefSymlinkOutsideRoot(t *testing.T) {
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	if err := os.Symlink(secret, filepath.Join(root, "link.txt")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	r := &Renderer{Root: root}
+	if got, err := r.expandFileRef("link.txt"); err == nil {
+		t.Errorf("expandFileRef through a symlink out of the root = %q, want an error", got)
+	}
+}
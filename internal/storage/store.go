@@ -1,16 +1,22 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/sunny/pmt/internal/config"
 	"github.com/sunny/pmt/internal/models"
 	"github.com/sunny/pmt/internal/utils"
 	"gopkg.in/yaml.v3"
 )
 
+// ErrAmbiguous is wrapped by lookups whose reference matches several prompts
+var ErrAmbiguous = errors.New("ambiguous")
+
 // FilterOptions defines the options for filtering prompts
 type FilterOptions struct {
 	Type          string
@@ -18,6 +24,11 @@ type FilterOptions struct {
 	Context       string
 	ContextPrefix bool // If true, match context as a prefix (e.g., "backend" matches "backend/api")
 	Tags          []string
+	IncludeGlobal bool // If true, global prompts match any Project filter
+
+	Branch            string // Only prompts pushed on this branch
+	IncludeUnbranched bool   // If true, prompts not tied to a branch match any Branch filter
+	Archived          bool   // If true, only archived prompts match; otherwise they never do
 }
 
 // Store interface defines the methods for prompt storage
@@ -25,34 +36,86 @@ type Store interface {
 	Save(p *models.Prompt) error
 	LoadAll() (*models.PromptStore, error)
 	FindByID(id string) (*models.Prompt, error)
+	FindByName(name string) (*models.Prompt, error)
 	Delete(id string) error
+	DeleteMany(ids []string) error
+	Trash(ids []string) error
+	Restore(ids []string) error
+	EmptyTrash() (int, error)
 	Filter(opts FilterOptions) ([]models.Prompt, error)
 	Update(id string, updater func(*models.Prompt)) error
 	BulkUpdate(updater func(*models.Prompt) bool) error
+	Modify(fn func(*models.PromptStore) error) error
 }
 
 // FileStore implements the Store interface using YAML files
 type FileStore struct {
 	filePath string
+	tags     models.TagPolicy // normalizes the tags of saved and updated prompts
+
+	// Inside a repository whose .pmt.yaml sets prompt_dir, the repository's
+	// own store is read on top of the user's. New prompts and context records
+	// of repoProject are written to it; everything else stays where it is.
+	repoPath    string
+	repoProject string
 }
 
-// NewFileStore creates a new FileStore instance
+// NewFileStore creates a new FileStore instance: the user's store, or with a
+// profile configured the profile's store under ~/.pmt/profiles. Inside a
+// repository whose .pmt.yaml sets prompt_dir, the repository's own store is
+// layered on top of it. Nothing is created until the store is written.
 func NewFileStore() (*FileStore, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := Paths(cfg.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &FileStore{filePath: paths[0], tags: cfg.TagPolicy()}
+	if len(paths) > 1 {
+		store.repoPath = paths[1]
+		store.repoProject = utils.DetectGitProject()
+	}
+	return store, nil
+}
+
+// Paths returns the files a store with the given profile reads: the user's
+// or profile's store, followed by the repository's own store when the
+// current repository has one
+func Paths(profile string) ([]string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
 
+	// Each profile keeps its prompts in a store of its own
 	pmtDir := filepath.Join(homeDir, ".pmt")
-	if err := os.MkdirAll(pmtDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create .pmt directory: %w", err)
+	if profile != "" {
+		pmtDir = filepath.Join(pmtDir, "profiles", profile)
 	}
+	paths := []string{filepath.Join(pmtDir, "prompts.yaml")}
 
-	filePath := filepath.Join(pmtDir, "prompts.yaml")
-	return &FileStore{filePath: filePath}, nil
+	repo, err := config.CurrentRepo()
+	if err != nil {
+		return nil, err
+	}
+	if path := repo.StorePath(); path != "" {
+		paths = append(paths, path)
+	}
+	return paths, nil
 }
 
-// Save saves a prompt to the store
+// RepoPath returns the repository's own store, or "" when prompts are only
+// kept in the user's store
+func (s *FileStore) RepoPath() string {
+	return s.repoPath
+}
+
+// Save saves a prompt to the store, with its context and tags in canonical form
 func (s *FileStore) Save(p *models.Prompt) error {
 	store, err := s.LoadAll()
 	if err != nil {
@@ -60,30 +123,39 @@ func (s *FileStore) Save(p *models.Prompt) error {
 		store = &models.PromptStore{Prompts: []models.Prompt{}}
 	}
 
-	// Check for ID conflicts
-	for _, existing := range store.Prompts {
-		if existing.ID == p.ID {
-			return fmt.Errorf("prompt with ID %s already exists", p.ID)
-		}
+	// Check for ID conflicts, including trashed prompts that may be restored
+	if hasID(store.Prompts, p.ID) || hasID(store.Trash, p.ID) {
+		return fmt.Errorf("prompt with ID %s already exists", p.ID)
 	}
 
+	p.Context = models.NormalizeContext(p.Context)
+	p.Tags = s.tags.NormalizeTags(p.Tags)
 	store.Prompts = append(store.Prompts, *p)
 
-	data, err := yaml.Marshal(store)
-	if err != nil {
-		return fmt.Errorf("failed to marshal prompts: %w", err)
-	}
+	return s.write(store)
+}
 
-	if err := os.WriteFile(s.filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write prompts file: %w", err)
+// LoadAll loads all prompts from the store, with those of the repository's
+// own store after the user's
+func (s *FileStore) LoadAll() (*models.PromptStore, error) {
+	store, err := readFile(s.filePath)
+	if err != nil || s.repoPath == "" {
+		return store, err
 	}
 
-	return nil
+	repo, err := readFile(s.repoPath)
+	if err != nil {
+		return nil, err
+	}
+	store.Prompts = append(store.Prompts, repo.Prompts...)
+	store.Trash = append(store.Trash, repo.Trash...)
+	store.Contexts = append(store.Contexts, repo.Contexts...)
+	return store, nil
 }
 
-// LoadAll loads all prompts from the store
-func (s *FileStore) LoadAll() (*models.PromptStore, error) {
-	data, err := os.ReadFile(s.filePath)
+// readFile reads one prompts file; a missing file is an empty store
+func readFile(path string) (*models.PromptStore, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &models.PromptStore{Prompts: []models.Prompt{}}, nil
@@ -93,7 +165,15 @@ func (s *FileStore) LoadAll() (*models.PromptStore, error) {
 
 	var store models.PromptStore
 	if err := yaml.Unmarshal(data, &store); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal prompts: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal prompts %s: %w", path, err)
+	}
+
+	// Chat prompts of earlier versions are rewritten on the next save
+	for i := range store.Prompts {
+		store.Prompts[i].ConvertChat()
+	}
+	for i := range store.Trash {
+		store.Trash[i].ConvertChat()
 	}
 
 	return &store, nil
@@ -124,6 +204,31 @@ func (s *FileStore) FindByID(id string) (*models.Prompt, error) {
 	return matches[0], nil
 }
 
+// FindByName finds a prompt by its name (case-insensitive)
+func (s *FileStore) FindByName(name string) (*models.Prompt, error) {
+	store, err := s.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*models.Prompt
+	for i := range store.Prompts {
+		if store.Prompts[i].Name != "" && strings.EqualFold(store.Prompts[i].Name, name) {
+			matches = append(matches, &store.Prompts[i])
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("prompt with name %s not found", name)
+	}
+
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("%w name %s: matches %s", ErrAmbiguous, name, joinIDs(matches))
+	}
+
+	return matches[0], nil
+}
+
 // Delete deletes a prompt by its ID or ID prefix
 func (s *FileStore) Delete(id string) error {
 	store, err := s.LoadAll()
@@ -152,12 +257,234 @@ func (s *FileStore) Delete(id string) error {
 	// Remove the prompt
 	store.Prompts = append(store.Prompts[:matchIndex], store.Prompts[matchIndex+1:]...)
 
+	return s.write(store)
+}
+
+// DeleteMany deletes several prompts by ID or ID prefix in a single write.
+// Nothing is deleted unless every ID matches exactly one prompt.
+func (s *FileStore) DeleteMany(ids []string) error {
+	store, err := s.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	remove, err := matchIDs(store.Prompts, ids)
+	if err != nil {
+		return err
+	}
+	store.Prompts, _ = split(store.Prompts, remove)
+
+	return s.write(store)
+}
+
+// Trash moves several prompts to the trash in a single write, where Restore
+// can bring them back. Nothing is moved unless every ID matches exactly one prompt.
+func (s *FileStore) Trash(ids []string) error {
+	store, err := s.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	remove, err := matchIDs(store.Prompts, ids)
+	if err != nil {
+		return err
+	}
+
+	MoveToTrash(store, remove)
+
+	return s.write(store)
+}
+
+// MoveToTrash moves the prompts at the given indexes to the trash of a loaded store
+func MoveToTrash(store *models.PromptStore, indexes map[int]bool) {
+	var trashed []models.Prompt
+	store.Prompts, trashed = split(store.Prompts, indexes)
+	now := time.Now()
+	for i := range trashed {
+		trashed[i].DeletedAt = now
+	}
+	store.Trash = append(store.Trash, trashed...)
+}
+
+// Restore moves prompts from the trash back into the store
+func (s *FileStore) Restore(ids []string) error {
+	store, err := s.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	restore, err := matchIDs(store.Trash, ids)
+	if err != nil {
+		return err
+	}
+
+	var restored []models.Prompt
+	store.Trash, restored = split(store.Trash, restore)
+	for i := range restored {
+		if hasID(store.Prompts, restored[i].ID) || hasID(restored[:i], restored[i].ID) {
+			return fmt.Errorf("cannot restore %s: a prompt with that ID already exists", restored[i].ID)
+		}
+		restored[i].DeletedAt = time.Time{}
+	}
+	store.Prompts = append(store.Prompts, restored...)
+
+	return s.write(store)
+}
+
+// EmptyTrash permanently deletes the prompts in the trash and returns how many there were
+func (s *FileStore) EmptyTrash() (int, error) {
+	store, err := s.LoadAll()
+	if err != nil {
+		return 0, err
+	}
+
+	count := len(store.Trash)
+	if count == 0 {
+		return 0, nil
+	}
+	store.Trash = nil
+
+	return count, s.write(store)
+}
+
+// hasID reports whether a prompt with the given ID is among prompts
+func hasID(prompts []models.Prompt, id string) bool {
+	for i := range prompts {
+		if prompts[i].ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRecord reports whether records holds the context record c, also after
+// it was renamed: records are identified by project and creation time
+func hasRecord(records []models.Context, c *models.Context) bool {
+	for i := range records {
+		r := &records[i]
+		if r.Project == c.Project && (r.Path == c.Path || (!c.CreatedAt.IsZero() && r.CreatedAt.Equal(c.CreatedAt))) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchIDs returns the indexes of the prompts matching each ID or ID prefix,
+// failing unless every ID matches exactly one prompt
+func matchIDs(prompts []models.Prompt, ids []string) (map[int]bool, error) {
+	matched := make(map[int]bool)
+	for _, id := range ids {
+		matchIndex := -1
+		matchCount := 0
+		for i := range prompts {
+			if utils.MatchIDPrefix(prompts[i].ID, id) {
+				matchIndex = i
+				matchCount++
+			}
+		}
+
+		if matchCount == 0 {
+			return nil, fmt.Errorf("prompt with ID %s not found", id)
+		}
+		if matchCount > 1 {
+			return nil, fmt.Errorf("ambiguous ID %s: matches multiple prompts", id)
+		}
+		matched[matchIndex] = true
+	}
+	return matched, nil
+}
+
+// joinIDs lists the IDs of prompts for error messages
+func joinIDs(prompts []*models.Prompt) string {
+	ids := make([]string, len(prompts))
+	for i, p := range prompts {
+		ids[i] = p.ID
+	}
+	return strings.Join(ids, ", ")
+}
+
+// split separates the prompts at the given indexes from the rest, keeping their order
+func split(prompts []models.Prompt, indexes map[int]bool) (kept, removed []models.Prompt) {
+	kept = make([]models.Prompt, 0, len(prompts)-len(indexes))
+	for i, p := range prompts {
+		if indexes[i] {
+			removed = append(removed, p)
+		} else {
+			kept = append(kept, p)
+		}
+	}
+	return kept, removed
+}
+
+// write saves the whole store. With a repository store, prompts and context
+// records go back to the file they were read from, and new ones of the
+// repository's project go to the repository store; types stay in the user's.
+func (s *FileStore) write(store *models.PromptStore) error {
+	if s.repoPath == "" {
+		return writeFile(s.filePath, store)
+	}
+
+	user, err := readFile(s.filePath)
+	if err != nil {
+		return err
+	}
+	repo, err := readFile(s.repoPath)
+	if err != nil {
+		return err
+	}
+	// A prompt or record stays in the file it is in; a new one goes to the
+	// repository store if it belongs to the repository's project
+	inRepo := func(project string, inUser, inRepoFile bool) bool {
+		return inRepoFile || (!inUser && strings.EqualFold(project, s.repoProject))
+	}
+	promptInRepo := func(p *models.Prompt) bool {
+		return inRepo(p.Project,
+			hasID(user.Prompts, p.ID) || hasID(user.Trash, p.ID),
+			hasID(repo.Prompts, p.ID) || hasID(repo.Trash, p.ID))
+	}
+
+	userStore := &models.PromptStore{Prompts: []models.Prompt{}, Types: store.Types}
+	repoStore := &models.PromptStore{Prompts: []models.Prompt{}}
+	for _, p := range store.Prompts {
+		if promptInRepo(&p) {
+			repoStore.Prompts = append(repoStore.Prompts, p)
+		} else {
+			userStore.Prompts = append(userStore.Prompts, p)
+		}
+	}
+	for _, p := range store.Trash {
+		if promptInRepo(&p) {
+			repoStore.Trash = append(repoStore.Trash, p)
+		} else {
+			userStore.Trash = append(userStore.Trash, p)
+		}
+	}
+	for _, c := range store.Contexts {
+		if inRepo(c.Project, hasRecord(user.Contexts, &c), hasRecord(repo.Contexts, &c)) {
+			repoStore.Contexts = append(repoStore.Contexts, c)
+		} else {
+			userStore.Contexts = append(userStore.Contexts, c)
+		}
+	}
+
+	if err := writeFile(s.filePath, userStore); err != nil {
+		return err
+	}
+	return writeFile(s.repoPath, repoStore)
+}
+
+// writeFile saves a store to one prompts file, creating its directory
+func writeFile(path string, store *models.PromptStore) error {
 	data, err := yaml.Marshal(store)
 	if err != nil {
 		return fmt.Errorf("failed to marshal prompts: %w", err)
 	}
 
-	if err := os.WriteFile(s.filePath, data, 0644); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create prompt directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write prompts file: %w", err)
 	}
 
@@ -179,7 +506,18 @@ func (s *FileStore) Filter(opts FilterOptions) ([]models.Prompt, error) {
 		}
 
 		// Filter by project
-		if opts.Project != "" && !strings.EqualFold(p.Project, opts.Project) {
+		if opts.Project != "" && !p.InProject(opts.Project) && !(opts.IncludeGlobal && p.Global) {
+			continue
+		}
+
+		// Filter by branch; global prompts are not tied to one either
+		if opts.Branch != "" && p.Branch != opts.Branch &&
+			!(opts.IncludeUnbranched && (p.Branch == "" || (opts.IncludeGlobal && p.Global))) {
+			continue
+		}
+
+		// Archived prompts are only listed on request
+		if p.Archived != opts.Archived {
 			continue
 		}
 
@@ -191,8 +529,8 @@ func (s *FileStore) Filter(opts FilterOptions) ([]models.Prompt, error) {
 					continue
 				}
 			} else {
-				// Exact matching
-				if !strings.EqualFold(p.Context, opts.Context) {
+				// Exact matching of the canonical forms
+				if models.NormalizeContext(p.Context) != models.NormalizeContext(opts.Context) {
 					continue
 				}
 			}
@@ -202,14 +540,8 @@ func (s *FileStore) Filter(opts FilterOptions) ([]models.Prompt, error) {
 		if len(opts.Tags) > 0 {
 			hasAllTags := true
 			for _, filterTag := range opts.Tags {
-				found := false
-				for _, pTag := range p.Tags {
-					if strings.EqualFold(pTag, filterTag) {
-						found = true
-						break
-					}
-				}
-				if !found {
+				// Synonyms resolve on query as on write; sub-tags match their parent
+				if !s.tags.Matches(p.Tags, filterTag) {
 					hasAllTags = false
 					break
 				}
@@ -250,20 +582,12 @@ func (s *FileStore) Update(id string, updater func(*models.Prompt)) error {
 		return fmt.Errorf("ambiguous ID %s: matches multiple prompts", id)
 	}
 
-	// Apply the updater function
+	// Apply the updater function, keeping the context and tags canonical
 	updater(&store.Prompts[matchIndex])
+	store.Prompts[matchIndex].Context = models.NormalizeContext(store.Prompts[matchIndex].Context)
+	store.Prompts[matchIndex].Tags = s.tags.NormalizeTags(store.Prompts[matchIndex].Tags)
 
-	// Save the updated store
-	data, err := yaml.Marshal(store)
-	if err != nil {
-		return fmt.Errorf("failed to marshal prompts: %w", err)
-	}
-
-	if err := os.WriteFile(s.filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write prompts file: %w", err)
-	}
-
-	return nil
+	return s.write(store)
 }
 
 // BulkUpdate updates multiple prompts based on a condition
@@ -277,6 +601,8 @@ func (s *FileStore) BulkUpdate(updater func(*models.Prompt) bool) error {
 	updateCount := 0
 	for i := range store.Prompts {
 		if updater(&store.Prompts[i]) {
+			store.Prompts[i].Context = models.NormalizeContext(store.Prompts[i].Context)
+			store.Prompts[i].Tags = s.tags.NormalizeTags(store.Prompts[i].Tags)
 			updateCount++
 		}
 	}
@@ -285,15 +611,20 @@ func (s *FileStore) BulkUpdate(updater func(*models.Prompt) bool) error {
 		return fmt.Errorf("no prompts matched the update criteria")
 	}
 
-	// Save the updated store
-	data, err := yaml.Marshal(store)
+	return s.write(store)
+}
+
+// Modify applies changes to prompts and context records together in a single
+// write. Nothing is written if fn returns an error.
+func (s *FileStore) Modify(fn func(*models.PromptStore) error) error {
+	store, err := s.LoadAll()
 	if err != nil {
-		return fmt.Errorf("failed to marshal prompts: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(s.filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write prompts file: %w", err)
+	if err := fn(store); err != nil {
+		return err
 	}
 
-	return nil
+	return s.write(store)
 }
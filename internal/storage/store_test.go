@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+func TestLayeredWrite(t *testing.T) {
+	dir := t.TempDir()
+	s := &FileStore{
+		filePath:    filepath.Join(dir, "user", "prompts.yaml"),
+		repoPath:    filepath.Join(dir, "repo", ".pmt", "prompts.yaml"),
+		repoProject: "github.com/org/api",
+	}
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Prompts saved before the repository had its own store stay in the user's
+	if err := writeFile(s.filePath, &models.PromptStore{
+		Prompts:  []models.Prompt{{ID: "old0001", Project: "github.com/org/api"}},
+		Contexts: []models.Context{{Path: "notes", Project: "github.com/org/api", CreatedAt: created}},
+		Types:    []models.PromptType{{Name: "general"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []models.Prompt{
+		{ID: "new0001", Project: "github.com/org/api"},
+		{ID: "oth0001", Project: "github.com/org/web"},
+	} {
+		if err := s.Save(&p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := s.Modify(func(store *models.PromptStore) error {
+		store.Contexts = append(store.Contexts, models.Context{Path: "docs", Project: "github.com/org/api"})
+		// A renamed record stays where it was
+		store.Contexts[0].Path = "journal"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := readFile(s.filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := readFile(s.repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := ids(user.Prompts); got != "old0001,oth0001" {
+		t.Errorf("user store prompts = %s, want old0001,oth0001", got)
+	}
+	if got := ids(repo.Prompts); got != "new0001" {
+		t.Errorf("repository store prompts = %s, want new0001", got)
+	}
+	if len(user.Contexts) != 1 || user.Contexts[0].Path != "journal" {
+		t.Errorf("user store contexts = %v, want journal", user.Contexts)
+	}
+	if len(repo.Contexts) != 1 || repo.Contexts[0].Path != "docs" {
+		t.Errorf("repository store contexts = %v, want docs", repo.Contexts)
+	}
+	if len(user.Types) != 1 || len(repo.Types) != 0 {
+		t.Errorf("types = %v in the user store and %v in the repository store, want them in the user store only", user.Types, repo.Types)
+	}
+
+	all, err := s.LoadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(all.Prompts); got != "old0001,oth0001,new0001" {
+		t.Errorf("LoadAll prompts = %s, want old0001,oth0001,new0001", got)
+	}
+
+	// IDs are unique across both stores
+	if err := s.Save(&models.Prompt{ID: "old0001", Project: "github.com/org/api"}); err == nil {
+		t.Error("Save accepted an ID that is already in the user store")
+	}
+}
+
+func ids(prompts []models.Prompt) string {
+	s := ""
+	for i, p := range prompts {
+		if i > 0 {
+			s += ","
+		}
+		s += p.ID
+	}
+	return s
+}
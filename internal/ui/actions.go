@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+// Action is a command bound to a key in the picker, run on the highlighted prompt
+type Action struct {
+	Key   rune
+	Label string
+
+	Confirm bool                          // ask "y/N" before running
+	Input   string                        // when set, ask for a line of input with this label first
+	Default func(p *models.Prompt) string // pre-filled input value
+	Suspend bool                          // leave raw mode while running, e.g. to open an editor
+
+	// Run performs the action and returns a status message for the picker
+	Run func(p *models.Prompt, input string) (string, error)
+}
+
+// modes shared by the picker and the browser
+const (
+	modeBrowse = iota
+	modeSearch
+	modeConfirm
+	modeInput
+)
+
+// actionState tracks an action from its key press, through confirmation or
+// input, until it runs
+type actionState struct {
+	actions []Action
+	mode    int
+
+	pending *Action // action waiting for confirmation or input
+	ready   *Action // action to run on the next loop iteration
+	input   string  // text typed in modeInput
+	status  string  // result of the last action
+}
+
+// action returns the action bound to a key, if any
+func (s *actionState) action(r rune) *Action {
+	for i := range s.actions {
+		if s.actions[i].Key == r {
+			return &s.actions[i]
+		}
+	}
+	return nil
+}
+
+// start asks for confirmation or input as needed, then marks the action ready
+func (s *actionState) start(a *Action, cur *models.Prompt) {
+	if cur == nil {
+		return
+	}
+
+	s.status = ""
+	switch {
+	case a.Confirm:
+		s.pending = a
+		s.mode = modeConfirm
+	case a.Input != "":
+		s.pending = a
+		s.mode = modeInput
+		s.input = ""
+		if a.Default != nil {
+			s.input = a.Default(cur)
+		}
+	default:
+		s.ready = a
+	}
+}
+
+// handleKey handles a key press while confirming or typing input, and
+// reports whether it consumed the key
+func (s *actionState) handleKey(k keyPress) bool {
+	switch s.mode {
+	case modeConfirm:
+		s.mode = modeBrowse
+		if k.key == keyRune && (k.char == 'y' || k.char == 'Y') {
+			s.ready = s.pending
+		} else {
+			s.status = "Cancelled"
+		}
+		s.pending = nil
+		return true
+
+	case modeInput:
+		switch k.key {
+		case keyEnter:
+			s.mode = modeBrowse
+			s.ready = s.pending
+			s.pending = nil
+		case keyEscape:
+			s.mode = modeBrowse
+			s.pending = nil
+			s.input = ""
+			s.status = "Cancelled"
+		case keyBackspace:
+			s.input = trimLastRune(s.input)
+		case keyRune:
+			s.input += string(k.char)
+		}
+		return true
+	}
+	return false
+}
+
+// runAction executes the ready action on target, leaving raw mode around it when
+// the action asks for it
+func (s *actionState) runAction(t *terminal, target *models.Prompt) error {
+	action := s.ready
+	s.ready = nil
+
+	if action.Suspend {
+		t.suspend()
+	}
+	msg, err := action.Run(target, s.input)
+	if action.Suspend {
+		if resumeErr := t.resume(); resumeErr != nil {
+			return resumeErr
+		}
+	}
+	s.input = ""
+
+	if err != nil {
+		s.status = "✗ " + err.Error()
+	} else {
+		s.status = msg
+	}
+	return nil
+}
+
+// promptLine returns the confirmation or input line, or "" when neither is active
+func (s *actionState) promptLine(cur *models.Prompt) string {
+	switch s.mode {
+	case modeConfirm:
+		id := ""
+		if cur != nil {
+			id = " " + cur.ID
+		}
+		return yellow(fmt.Sprintf("%s%s? (y/N)", s.pending.Label, id))
+	case modeInput:
+		return yellow(s.pending.Input+": ") + s.input + "█"
+	}
+	return ""
+}
+
+// keysHelp lists the action keys, e.g. "e edit • d delete"
+func (s *actionState) keysHelp() string {
+	keys := make([]string, len(s.actions))
+	for i, a := range s.actions {
+		keys[i] = fmt.Sprintf("%c %s", a.Key, strings.ToLower(a.Label))
+	}
+	return strings.Join(keys, " • ")
+}
+
+// trimLastRune removes the last character of s
+func trimLastRune(s string) string {
+	if s == "" {
+		return s
+	}
+	_, size := utf8.DecodeLastRuneInString(s)
+	return s[:len(s)-size]
+}
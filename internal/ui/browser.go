@@ -0,0 +1,729 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+// BrowserOptions configures Browse
+type BrowserOptions struct {
+	Actions []Action
+	// Create writes a new prompt in the given context and returns a status message
+	Create func(context string) (string, error)
+	// Reload refreshes the prompts after an action has run
+	Reload func() ([]models.Prompt, error)
+}
+
+// panes that can have the focus
+const (
+	paneTree = iota
+	paneList
+)
+
+// kinds of rows in the context pane
+const (
+	rowAll = iota
+	rowContext
+	rowNone
+)
+
+// treeRow is one line of the context pane
+type treeRow struct {
+	kind  int
+	label string
+	path  string
+	depth int
+	count int
+}
+
+// browser is the full-screen prompt browser behind 'pmt ui'
+type browser struct {
+	all    []models.Prompt
+	create func(context string) (string, error)
+	reload func() ([]models.Prompt, error)
+
+	focus    int
+	rows     []treeRow
+	row      int // highlighted row in the context pane
+	rowTop   int // first context row shown
+	visible  []int
+	cursor   int // position in visible
+	top      int // first prompt row shown
+	scroll   int // first preview line shown
+	query    string
+	creating bool
+
+	// Filter chips; empty means any
+	typeChip    string
+	tagChip     string
+	projectChip string
+
+	actionState
+}
+
+// Browse shows a full-screen browser with a context tree, a filtered prompt
+// list and a preview pane. It returns the prompt chosen with enter, or nil
+// when the user quits.
+func Browse(prompts []models.Prompt, opts BrowserOptions) (*models.Prompt, error) {
+	if !IsInteractive() {
+		return nil, fmt.Errorf("the browser needs an interactive terminal")
+	}
+
+	t, err := openTerminal()
+	if err != nil {
+		return nil, err
+	}
+	defer t.close()
+
+	t.enterFullscreen()
+	defer t.exitFullscreen()
+
+	b := &browser{
+		all:    prompts,
+		create: opts.Create,
+		reload: opts.Reload,
+		focus:  paneList,
+	}
+	b.actions = opts.Actions
+	b.rebuild()
+
+	for {
+		width, height := t.size()
+		t.drawScreen(b.render(width, height))
+
+		k, err := t.readKey()
+		if err != nil {
+			return nil, err
+		}
+
+		selected, done := b.handle(k, height)
+		if done {
+			return selected, nil
+		}
+
+		if b.ready != nil || b.creating {
+			if err := b.execute(t); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// execute runs the ready action, or creates a prompt, then reloads the prompts
+func (b *browser) execute(t *terminal) error {
+	keepID := ""
+	if cur := b.current(); cur != nil {
+		keepID = cur.ID
+	}
+
+	if b.creating {
+		b.creating = false
+		t.suspend()
+		msg, err := b.create(b.context())
+		if resumeErr := t.resume(); resumeErr != nil {
+			return resumeErr
+		}
+		if err != nil {
+			b.status = "✗ " + err.Error()
+		} else {
+			b.status = msg
+		}
+	} else {
+		cur := b.current()
+		if cur == nil {
+			b.ready = nil
+			return nil
+		}
+		target := *cur
+		if err := b.runAction(t, &target); err != nil {
+			return err
+		}
+	}
+
+	if b.reload != nil {
+		prompts, err := b.reload()
+		if err != nil {
+			b.status = "✗ " + err.Error()
+			return nil
+		}
+		b.all = prompts
+		b.visible = nil
+		b.rebuild()
+		b.keep(keepID)
+	}
+	return nil
+}
+
+// handle applies a key press. It reports true when the browser should close,
+// along with the chosen prompt, if any.
+func (b *browser) handle(k keyPress, height int) (*models.Prompt, bool) {
+	if k.key == keyCtrlC {
+		return nil, true
+	}
+	if b.handleKey(k) {
+		return nil, false
+	}
+
+	page := max(height-5, 1)
+
+	if b.mode == modeSearch {
+		switch k.key {
+		case keyEnter:
+			b.mode = modeBrowse
+		case keyEscape:
+			b.mode = modeBrowse
+			b.query = ""
+			b.filter()
+		case keyBackspace:
+			b.query = trimLastRune(b.query)
+			b.filter()
+		case keyRune:
+			b.query += string(k.char)
+			b.filter()
+		case keyUp:
+			b.moveList(-1)
+		case keyDown:
+			b.moveList(1)
+		}
+		return nil, false
+	}
+
+	switch k.key {
+	case keyEscape:
+		if b.query == "" {
+			return nil, true
+		}
+		b.query = ""
+		b.filter()
+	case keyTab:
+		b.focus = 1 - b.focus
+	case keyLeft:
+		b.focus = paneTree
+	case keyRight:
+		b.focus = paneList
+	case keyUp:
+		b.move(-1)
+	case keyDown:
+		b.move(1)
+	case keyHome:
+		b.move(-len(b.all) - len(b.rows))
+	case keyEnd:
+		b.move(len(b.all) + len(b.rows))
+	case keyPageUp:
+		b.scroll -= page / 2
+	case keyPageDown:
+		b.scroll += page / 2
+	case keyEnter:
+		if b.focus == paneTree {
+			b.focus = paneList
+			return nil, false
+		}
+		if cur := b.current(); cur != nil {
+			selected := *cur
+			return &selected, true
+		}
+	case keyRune:
+		switch k.char {
+		case 'q':
+			return nil, true
+		case '/':
+			b.mode = modeSearch
+			b.focus = paneList
+		case 'k':
+			b.move(-1)
+		case 'j':
+			b.move(1)
+		case 'h':
+			b.focus = paneTree
+		case 'l':
+			b.focus = paneList
+		case 'K':
+			b.scroll--
+		case 'J':
+			b.scroll++
+		case 'T':
+			b.typeChip = nextChip(b.values(func(p models.Prompt) []string { return []string{p.Type} }), b.typeChip)
+			b.rebuild()
+		case '#':
+			b.tagChip = nextChip(b.values(func(p models.Prompt) []string { return p.Tags }), b.tagChip)
+			b.rebuild()
+		case 'P':
+			b.projectChip = nextChip(b.projectIDs(), b.projectChip)
+			b.rebuild()
+		case 'x':
+			b.typeChip, b.tagChip, b.projectChip = "", "", ""
+			b.rebuild()
+		case 'n':
+			if b.create != nil {
+				b.status = ""
+				b.creating = true
+			}
+		default:
+			if a := b.action(k.char); a != nil {
+				b.start(a, b.current())
+			}
+		}
+	}
+	return nil, false
+}
+
+// move moves the cursor of the focused pane
+func (b *browser) move(delta int) {
+	if b.focus == paneTree {
+		b.row = clamp(b.row+delta, 0, len(b.rows)-1)
+		b.filter()
+		return
+	}
+	b.moveList(delta)
+}
+
+// moveList moves the prompt cursor and resets the preview scroll
+func (b *browser) moveList(delta int) {
+	cursor := clamp(b.cursor+delta, 0, len(b.visible)-1)
+	if cursor != b.cursor {
+		b.scroll = 0
+	}
+	b.cursor = cursor
+}
+
+// current returns the highlighted prompt, if any
+func (b *browser) current() *models.Prompt {
+	if len(b.visible) == 0 {
+		return nil
+	}
+	return &b.all[b.visible[b.cursor]]
+}
+
+// context returns the context path highlighted in the tree, for new prompts
+func (b *browser) context() string {
+	if len(b.rows) == 0 || b.rows[b.row].kind != rowContext {
+		return ""
+	}
+	return b.rows[b.row].path
+}
+
+// keep moves the prompt cursor to the prompt with the given ID, if visible
+func (b *browser) keep(id string) {
+	for i, idx := range b.visible {
+		if b.all[idx].ID == id {
+			b.cursor = i
+			return
+		}
+	}
+}
+
+// values collects the distinct, sorted values of a prompt field
+func (b *browser) values(field func(p models.Prompt) []string) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, p := range b.all {
+		for _, v := range field(p) {
+			if v != "" && !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// projectIDs returns the distinct project IDs, sorted by their chip labels
+func (b *browser) projectIDs() []string {
+	ids := b.values(func(p models.Prompt) []string { return []string{p.Project} })
+	sort.SliceStable(ids, func(i, j int) bool { return b.projectLabel(ids[i]) < b.projectLabel(ids[j]) })
+	return ids
+}
+
+// projectLabel returns the display name of a project for its chip, with the
+// ID added when another project has the same display name
+func (b *browser) projectLabel(id string) string {
+	name := id
+	for _, p := range b.all {
+		if p.Project == id {
+			name = p.DisplayProject()
+			break
+		}
+	}
+	for _, p := range b.all {
+		if p.Project != id && p.DisplayProject() == name {
+			return name + " (" + id + ")"
+		}
+	}
+	return name
+}
+
+// nextChip cycles a filter chip through the available values and back to any
+func nextChip(values []string, current string) string {
+	for i, v := range values {
+		if v == current && i+1 < len(values) {
+			return values[i+1]
+		}
+		if v == current {
+			return ""
+		}
+	}
+	if current == "" && len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// matchesChips reports whether a prompt passes the type, tag and project chips
+func (b *browser) matchesChips(p models.Prompt) bool {
+	if b.typeChip != "" && p.Type != b.typeChip {
+		return false
+	}
+	if b.projectChip != "" && p.Project != b.projectChip {
+		return false
+	}
+	if b.tagChip != "" {
+		for _, tag := range p.Tags {
+			if tag == b.tagChip {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// rebuild recomputes the context tree from the prompts that pass the chips,
+// keeping the highlighted row where possible, then refilters the list
+func (b *browser) rebuild() {
+	var prev treeRow
+	if b.row < len(b.rows) {
+		prev = b.rows[b.row]
+	}
+
+	var matching []models.Prompt
+	for _, p := range b.all {
+		if b.matchesChips(p) {
+			matching = append(matching, p)
+		}
+	}
+
+	root, noContext := models.BuildContextTree(matching)
+	b.rows = []treeRow{{kind: rowAll, label: "All prompts", count: len(matching)}}
+
+	var walk func(node *models.TreeNode, depth int)
+	walk = func(node *models.TreeNode, depth int) {
+		for _, child := range node.SortedChildren() {
+			b.rows = append(b.rows, treeRow{
+				kind:  rowContext,
+				label: child.Name,
+				path:  child.Path,
+				depth: depth,
+				count: child.Prompts,
+			})
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 0)
+
+	if noContext > 0 {
+		b.rows = append(b.rows, treeRow{kind: rowNone, label: "(no context)", count: noContext})
+	}
+
+	b.row = 0
+	for i, r := range b.rows {
+		if r.kind == prev.kind && r.path == prev.path {
+			b.row = i
+			break
+		}
+	}
+	b.filter()
+}
+
+// filter recomputes the visible prompts from the chips, the highlighted
+// context and the search query, keeping the cursor on the same prompt
+func (b *browser) filter() {
+	keepID := ""
+	if cur := b.current(); cur != nil {
+		keepID = cur.ID
+	}
+
+	row := b.rows[b.row]
+	query := strings.Replace(strings.ToLower(b.query), " ", "", -1)
+
+	b.visible = b.visible[:0]
+	for i, p := range b.all {
+		if !b.matchesChips(p) {
+			continue
+		}
+		switch row.kind {
+		case rowContext:
+			if !p.MatchesContextPrefix(row.path) {
+				continue
+			}
+		case rowNone:
+			if p.Context != "" {
+				continue
+			}
+		}
+		if query != "" && !strings.Contains(searchText(p), query) {
+			continue
+		}
+		b.visible = append(b.visible, i)
+	}
+
+	b.cursor = 0
+	b.keep(keepID)
+	b.moveList(0)
+}
+
+// render builds the lines of one frame
+func (b *browser) render(width, height int) []string {
+	if width < 40 || height < 8 {
+		return []string{"Terminal too small for pmt ui"}
+	}
+
+	body := height - 3 // header, status and help lines
+	leftW := clamp(width/5, 18, 30)
+	midW := (width - leftW - 2) * 2 / 5
+	rightW := width - leftW - midW - 2
+
+	left := b.renderTree(leftW, body)
+	mid := b.renderList(midW, body)
+	right := b.renderPreview(rightW, body)
+
+	lines := []string{b.renderHeader()}
+	sep := faint("│")
+	for i := 0; i < body; i++ {
+		lines = append(lines, left[i]+sep+mid[i]+sep+right[i])
+	}
+
+	cur := b.current()
+	switch {
+	case b.promptLine(cur) != "":
+		lines = append(lines, b.promptLine(cur))
+	case b.status != "":
+		lines = append(lines, fit(b.status, width))
+	default:
+		lines = append(lines, faint(fit(fmt.Sprintf("%d of %d prompts", len(b.visible), len(b.all)), width)))
+	}
+
+	help := "tab pane • / search • T type • # tag • P project • x clear • enter copy • n new"
+	if len(b.actions) > 0 {
+		help += " • " + b.keysHelp()
+	}
+	help += " • q quit"
+	return append(lines, faint(fit(help, width)))
+}
+
+// projectChipLabel returns the label of the project chip, "" for any
+func (b *browser) projectChipLabel() string {
+	if b.projectChip == "" {
+		return ""
+	}
+	return b.projectLabel(b.projectChip)
+}
+
+// renderHeader shows the title, the filter chips and the search query
+func (b *browser) renderHeader() string {
+	chip := func(name, value string) string {
+		if value == "" {
+			return faint("[" + name + ": any]")
+		}
+		return invert(" " + name + ": " + value + " ")
+	}
+
+	header := bold(" pmt ") + " " +
+		chip("type", b.typeChip) + " " +
+		chip("tag", b.tagChip) + " " +
+		chip("project", b.projectChipLabel())
+
+	if b.mode == modeSearch || b.query != "" {
+		header += "  " + faint("Search:") + " " + b.query
+		if b.mode == modeSearch {
+			header += "█"
+		}
+	}
+	return header
+}
+
+// renderTree renders the context pane
+func (b *browser) renderTree(width, height int) []string {
+	lines := []string{b.title("Contexts", b.focus == paneTree, width)}
+
+	rows := height - 1
+	if b.row < b.rowTop {
+		b.rowTop = b.row
+	}
+	if b.row >= b.rowTop+rows {
+		b.rowTop = b.row - rows + 1
+	}
+
+	for i := b.rowTop; i < len(b.rows) && len(lines) < height; i++ {
+		r := b.rows[i]
+		text := fit(fmt.Sprintf(" %s%s (%d)", strings.Repeat("  ", r.depth), r.label, r.count), width)
+		switch {
+		case i == b.row && b.focus == paneTree:
+			text = invert(text)
+		case i == b.row:
+			text = bold(text)
+		}
+		lines = append(lines, text)
+	}
+	return padLines(lines, width, height)
+}
+
+// renderList renders the prompt pane
+func (b *browser) renderList(width, height int) []string {
+	lines := []string{b.title(fmt.Sprintf("Prompts (%d)", len(b.visible)), b.focus == paneList, width)}
+
+	rows := height - 1
+	if b.cursor < b.top {
+		b.top = b.cursor
+	}
+	if b.cursor >= b.top+rows {
+		b.top = b.cursor - rows + 1
+	}
+
+	if len(b.visible) == 0 {
+		lines = append(lines, faint(fit(" No matching prompts", width)))
+	}
+
+	for i := b.top; i < len(b.visible) && len(lines) < height; i++ {
+		p := b.all[b.visible[i]]
+		label := oneLine(p.Text(), width)
+		if p.Name != "" {
+			label = "[" + p.Name + "] " + label
+		}
+		id := " " + p.ID + " "
+		text := fit(label, max(width-len(id), 0))
+		switch {
+		case i == b.cursor && b.focus == paneList:
+			text = invert(id + text)
+		case i == b.cursor:
+			text = bold(cyan(id) + text)
+		default:
+			text = cyan(id) + text
+		}
+		lines = append(lines, text)
+	}
+	return padLines(lines, width, height)
+}
+
+// renderPreview renders the metadata and wrapped content of the current prompt
+func (b *browser) renderPreview(width, height int) []string {
+	cur := b.current()
+	if cur == nil {
+		return padLines([]string{b.title("Preview", false, width)}, width, height)
+	}
+
+	content := previewLines(cur, width-1)
+	rows := height - 1
+	b.scroll = clamp(b.scroll, 0, max(len(content)-rows, 0))
+
+	title := "Preview"
+	if len(content) > rows {
+		title = fmt.Sprintf("Preview %d-%d/%d", b.scroll+1, min(b.scroll+rows, len(content)), len(content))
+	}
+	lines := []string{b.title(title, false, width)}
+	for i := b.scroll; i < len(content) && len(lines) < height; i++ {
+		lines = append(lines, " "+fit(content[i], width-1))
+	}
+	return padLines(lines, width, height)
+}
+
+// previewLines lays out a prompt's metadata and content for the preview pane
+func previewLines(p *models.Prompt, width int) []string {
+	field := func(name, value string) string {
+		return fmt.Sprintf("%-9s %s", name+":", value)
+	}
+
+	lines := []string{field("ID", p.ID)}
+	if p.Name != "" {
+		lines = append(lines, field("Name", p.Name))
+	}
+	lines = append(lines, field("Type", p.Type), field("Project", p.DisplayProject()))
+	if p.Context != "" {
+		lines = append(lines, field("Context", p.Context))
+	}
+	if len(p.Tags) > 0 {
+		lines = append(lines, field("Tags", strings.Join(p.Tags, ", ")))
+	}
+	if p.Extends != "" {
+		lines = append(lines, field("Extends", p.Extends))
+	}
+	if len(p.Variants) > 0 {
+		lines = append(lines, field("Variants", strings.Join(p.VariantNames(), ", ")))
+	}
+	lines = append(lines, field("Created", p.CreatedAt.Format(dateFormat)), "")
+
+	var wrapped []string
+	for _, line := range lines {
+		wrapped = append(wrapped, wrap(line, width)...)
+	}
+	return append(wrapped, wrap(p.Text(), width)...)
+}
+
+// title renders a pane title, highlighted when the pane has the focus
+func (b *browser) title(text string, focused bool, width int) string {
+	text = fit(" "+text, width)
+	if focused {
+		return bold(cyan(text))
+	}
+	return faint(text)
+}
+
+// wrap breaks text into lines of at most width characters, preferring to
+// break at spaces
+func wrap(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n") {
+		runes := []rune(line)
+		for len(runes) > width {
+			cut := width
+			for i := width; i > width/2; i-- {
+				if runes[i] == ' ' {
+					cut = i
+					break
+				}
+			}
+			lines = append(lines, string(runes[:cut]))
+			runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+		}
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+// fit truncates or pads s to exactly width characters
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+// padLines fills a pane up to its height with blank lines
+func padLines(lines []string, width, height int) []string {
+	for len(lines) < height {
+		lines = append(lines, strings.Repeat(" ", width))
+	}
+	return lines
+}
+
+// clamp limits v to the range [lo, hi], preferring lo when the range is empty
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+func TestProjectChipUsesIDs(t *testing.T) {
+	b := &browser{all: []models.Prompt{
+		{ID: "a", Project: "github.com/acme/api", ProjectName: "api"},
+		{ID: "b", Project: "github.com/other/api", ProjectName: "api"},
+		{ID: "c", Project: "github.com/acme/web", ProjectName: "web"},
+	}}
+
+	b.projectChip = "github.com/acme/api"
+	tests := []struct {
+		id   string
+		want bool
+	}{{"a", true}, {"b", false}, {"c", false}}
+	for i, tt := range tests {
+		if got := b.matchesChips(b.all[i]); got != tt.want {
+			t.Errorf("matchesChips(%s) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+
+	if got, want := b.projectChipLabel(), "api (github.com/acme/api)"; got != want {
+		t.Errorf("label = %q, want %q", got, want)
+	}
+	if got, want := b.projectLabel("github.com/acme/web"), "web"; got != want {
+		t.Errorf("label = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,466 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+// ErrCancelled is returned when the user leaves a picker without choosing
+var ErrCancelled = errors.New("cancelled")
+
+// PickerOptions configures PickPrompts
+type PickerOptions struct {
+	Label   string
+	Multi   bool
+	Actions []Action
+	// Reload refreshes the list after an action has run
+	Reload func() ([]models.Prompt, error)
+}
+
+// picker is a raw-terminal prompt list with search, multi-selection and actions
+type picker struct {
+	label    string
+	all      []models.Prompt
+	multi    bool
+	pageSize int
+	reload   func() ([]models.Prompt, error)
+
+	visible []int    // indices into all that match the query
+	cursor  int      // position in visible
+	offset  int      // first row of visible shown on screen
+	marked  []string // IDs of marked prompts, in the order they were marked
+	query   string
+
+	actionState
+}
+
+// PickPrompts shows the picker with the given options. With Multi, prompts are
+// marked with space and returned in the order they were marked; confirming
+// with nothing marked only shows a hint. Action keys run on the
+// highlighted prompt and refresh the list in place without leaving the picker.
+// Without a terminal it falls back to reading numbers from stdin.
+func PickPrompts(prompts []models.Prompt, opts PickerOptions) ([]models.Prompt, error) {
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("no prompts available")
+	}
+
+	if !IsInteractive() {
+		if opts.Multi {
+			return selectManyFromList(prompts)
+		}
+		selected, err := selectFromList(prompts)
+		if err != nil {
+			return nil, err
+		}
+		return []models.Prompt{*selected}, nil
+	}
+
+	label := opts.Label
+	if label == "" {
+		label = "Select Prompt"
+		if opts.Multi {
+			label = "Select Prompts"
+		}
+	}
+
+	p := &picker{
+		label:    label,
+		all:      prompts,
+		multi:    opts.Multi,
+		pageSize: pageSize,
+		reload:   opts.Reload,
+	}
+	p.actions = opts.Actions
+	return p.run()
+}
+
+// run shows the picker until the user confirms or cancels
+func (p *picker) run() ([]models.Prompt, error) {
+	t, err := openTerminal()
+	if err != nil {
+		return nil, err
+	}
+	defer t.close()
+
+	p.filter()
+	for {
+		t.draw(p.render(t))
+
+		k, err := t.readKey()
+		if err != nil {
+			t.clear()
+			return nil, err
+		}
+
+		done, err := p.handle(k)
+		if err != nil {
+			t.clear()
+			return nil, err
+		}
+		if done {
+			t.clear()
+			return p.result(), nil
+		}
+
+		if p.ready != nil {
+			if err := p.execute(t); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// execute runs the ready action on the highlighted prompt and refreshes the list
+func (p *picker) execute(t *terminal) error {
+	cur := p.current()
+	if cur == nil {
+		p.ready = nil
+		return nil
+	}
+	target := *cur
+
+	if err := p.runAction(t, &target); err != nil {
+		return err
+	}
+
+	if p.reload != nil {
+		prompts, err := p.reload()
+		if err != nil {
+			p.status = "✗ " + err.Error()
+			return nil
+		}
+		p.refresh(prompts, target.ID)
+	}
+	return nil
+}
+
+// refresh replaces the prompt list, keeping the cursor on keepID when it still exists
+func (p *picker) refresh(prompts []models.Prompt, keepID string) {
+	p.all = prompts
+
+	// Drop marks of prompts that no longer exist
+	exists := make(map[string]bool, len(prompts))
+	for _, prompt := range prompts {
+		exists[prompt.ID] = true
+	}
+	marked := p.marked[:0]
+	for _, id := range p.marked {
+		if exists[id] {
+			marked = append(marked, id)
+		}
+	}
+	p.marked = marked
+
+	p.filter()
+	for row, i := range p.visible {
+		if p.all[i].ID == keepID {
+			p.cursor = row
+			p.move(0)
+			break
+		}
+	}
+}
+
+// handle applies a key press. It reports true once the selection is confirmed.
+func (p *picker) handle(k keyPress) (bool, error) {
+	if k.key == keyCtrlC {
+		return false, ErrCancelled
+	}
+
+	if p.handleKey(k) {
+		return false, nil
+	}
+
+	switch k.key {
+	case keyEscape:
+		if p.mode == modeSearch || p.query != "" {
+			p.mode = modeBrowse
+			p.query = ""
+			p.filter()
+			return false, nil
+		}
+		return false, ErrCancelled
+	case keyUp:
+		p.move(-1)
+	case keyDown, keyTab:
+		p.move(1)
+	case keyPageUp:
+		p.move(-p.pageSize)
+	case keyPageDown:
+		p.move(p.pageSize)
+	case keyHome:
+		p.move(-len(p.visible))
+	case keyEnd:
+		p.move(len(p.visible))
+	case keyEnter:
+		if p.mode == modeSearch {
+			// Leave search mode but keep the filter, so action keys work again
+			p.mode = modeBrowse
+			return false, nil
+		}
+		if p.multi && len(p.marked) == 0 {
+			// Confirming a multi-selection needs at least one explicit mark
+			p.status = "Nothing marked: press space to mark prompts"
+			return false, nil
+		}
+		return len(p.visible) > 0 || len(p.marked) > 0, nil
+	case keyBackspace:
+		if p.mode == modeSearch && p.query != "" {
+			p.query = trimLastRune(p.query)
+			p.filter()
+		} else if p.mode == modeSearch {
+			p.mode = modeBrowse
+		}
+	case keyRune:
+		if p.mode == modeSearch {
+			p.query += string(k.char)
+			p.filter()
+			return false, nil
+		}
+
+		switch {
+		case k.char == '/':
+			p.mode = modeSearch
+		case k.char == ' ' && p.multi:
+			p.status = ""
+			p.toggle()
+			p.move(1)
+		case k.char == 'k':
+			p.move(-1)
+		case k.char == 'j':
+			p.move(1)
+		case k.char == 'q':
+			return false, ErrCancelled
+		default:
+			if a := p.action(k.char); a != nil {
+				p.start(a, p.current())
+			}
+		}
+	}
+	return false, nil
+}
+
+// filter recomputes the visible prompts from the search query
+func (p *picker) filter() {
+	p.visible = p.visible[:0]
+	query := strings.Replace(strings.ToLower(p.query), " ", "", -1)
+	for i, prompt := range p.all {
+		if query == "" || strings.Contains(searchText(prompt), query) {
+			p.visible = append(p.visible, i)
+		}
+	}
+	p.move(0)
+}
+
+// move shifts the cursor by delta, keeping it and the page window in range
+func (p *picker) move(delta int) {
+	p.cursor += delta
+	if p.cursor >= len(p.visible) {
+		p.cursor = len(p.visible) - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+p.pageSize {
+		p.offset = p.cursor - p.pageSize + 1
+	}
+}
+
+// current returns the highlighted prompt, if any
+func (p *picker) current() *models.Prompt {
+	if len(p.visible) == 0 {
+		return nil
+	}
+	return &p.all[p.visible[p.cursor]]
+}
+
+// toggle marks or unmarks the highlighted prompt
+func (p *picker) toggle() {
+	cur := p.current()
+	if cur == nil {
+		return
+	}
+	for i, id := range p.marked {
+		if id == cur.ID {
+			p.marked = append(p.marked[:i], p.marked[i+1:]...)
+			return
+		}
+	}
+	p.marked = append(p.marked, cur.ID)
+}
+
+// markIndex returns the 1-based marking order of a prompt, or 0 when unmarked
+func (p *picker) markIndex(id string) int {
+	for i, marked := range p.marked {
+		if marked == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// result returns the marked prompts in marking order, or the highlighted one
+// of a single selection
+func (p *picker) result() []models.Prompt {
+	if !p.multi {
+		if cur := p.current(); cur != nil {
+			return []models.Prompt{*cur}
+		}
+		return nil
+	}
+
+	byID := make(map[string]models.Prompt, len(p.all))
+	for _, prompt := range p.all {
+		byID[prompt.ID] = prompt
+	}
+	selected := make([]models.Prompt, 0, len(p.marked))
+	for _, id := range p.marked {
+		selected = append(selected, byID[id])
+	}
+	return selected
+}
+
+// render builds the lines of one frame
+func (p *picker) render(t *terminal) []string {
+	width, height := t.size()
+	if p.pageSize > height-3 && height > 4 {
+		p.pageSize = height - 3
+		p.move(0)
+	}
+
+	header := "? " + bold(p.label)
+	if p.mode == modeSearch || p.query != "" {
+		header += " " + faint("Search:") + " " + p.query
+		if p.mode == modeSearch {
+			header += "█"
+		}
+	}
+	lines := []string{header}
+
+	if len(p.visible) == 0 {
+		lines = append(lines, faint("  No matching prompts"))
+	}
+
+	end := p.offset + p.pageSize
+	if end > len(p.visible) {
+		end = len(p.visible)
+	}
+	for row := p.offset; row < end; row++ {
+		lines = append(lines, p.renderItem(&p.all[p.visible[row]], row == p.cursor, width))
+	}
+
+	if cur := p.current(); cur != nil {
+		lines = append(lines, faint("  "+detailLine(cur, width-2)))
+	}
+
+	if line := p.promptLine(p.current()); line != "" {
+		return append(lines, line)
+	}
+
+	if p.status != "" {
+		lines = append(lines, p.status)
+	}
+
+	help := "↑/↓ move • / search • enter confirm • esc cancel"
+	if p.multi {
+		help = "↑/↓ move • space mark • / search • enter confirm • esc cancel"
+	}
+	if len(p.marked) > 0 {
+		help = fmt.Sprintf("%d marked • %s", len(p.marked), help)
+	}
+	lines = append(lines, faint(help))
+
+	if len(p.actions) > 0 {
+		lines = append(lines, faint(p.keysHelp()))
+	}
+	return lines
+}
+
+// detailLine summarizes where a prompt lives, for the line under the list
+func detailLine(p *models.Prompt, width int) string {
+	parts := []string{"project: " + p.DisplayProject()}
+	if p.Context != "" {
+		parts = append(parts, "context: "+p.Context)
+	}
+	if len(p.Tags) > 0 {
+		parts = append(parts, "tags: "+strings.Join(p.Tags, ", "))
+	}
+	parts = append(parts, "created: "+p.CreatedAt.Format(dateFormat))
+
+	line := strings.Join(parts, " • ")
+	if runes := []rune(line); width > 3 && len(runes) > width {
+		line = string(runes[:width-3]) + "..."
+	}
+	return line
+}
+
+// renderItem formats one prompt row, truncated to the terminal width
+func (p *picker) renderItem(prompt *models.Prompt, active bool, width int) string {
+	pointer := "  "
+	if active {
+		pointer = "▸ "
+	}
+
+	mark := ""
+	markWidth := 0
+	if p.multi {
+		if n := p.markIndex(prompt.ID); n > 0 {
+			mark = green(fmt.Sprintf("[%d]", n)) + " "
+			markWidth = len(strconv.Itoa(n)) + 3
+		} else {
+			mark = "[ ] "
+			markWidth = 4
+		}
+	}
+
+	name := ""
+	if prompt.Name != "" {
+		name = "[" + prompt.Name + "] "
+	}
+
+	// Budget the content for the remaining width
+	used := 2 + markWidth + len(prompt.ID) + 1 + len(name) + len(prompt.Type) + 3
+	content := oneLine(prompt.Text(), max(width-used-4, 10))
+
+	line := pointer + mark + cyan(prompt.ID) + " "
+	if name != "" {
+		line += "[" + green(prompt.Name) + "] "
+	}
+	return line + "(" + typeColor(prompt.Type) + ") " + content
+}
+
+// selectManyFromList is the non-interactive multi-selector: it reads numbers
+// or IDs separated by spaces or commas, keeping their order
+func selectManyFromList(prompts []models.Prompt) ([]models.Prompt, error) {
+	printList(prompts)
+	fmt.Fprintf(os.Stderr, "Select prompts (e.g. 1,3,2): ")
+
+	choice, err := readLine()
+	if err != nil || choice == "" {
+		return nil, fmt.Errorf("no interactive terminal and no selection on stdin; pass IDs from: %s", joinIDs(prompts))
+	}
+
+	var selected []models.Prompt
+	for _, field := range strings.FieldsFunc(choice, func(r rune) bool { return r == ',' || r == ' ' }) {
+		i, err := matchChoice(prompts, field)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, prompts[i])
+	}
+	return selected, nil
+}
+
+// searchText is the text the search query is matched against
+func searchText(p models.Prompt) string {
+	s := strings.ToLower(p.ID + p.Name + p.Type + p.Text() + strings.Join(p.Tags, " "))
+	return strings.Replace(s, " ", "", -1)
+}
@@ -2,72 +2,81 @@ package ui
 
 import (
 	"fmt"
-	"strings"
 
-	"github.com/manifoldco/promptui"
 	"github.com/sunny/pmt/internal/models"
 )
 
 // SelectPrompt displays an interactive prompt selector and returns the selected prompt
+// When not running on a terminal it falls back to a numbered list read from stdin.
 func SelectPrompt(prompts []models.Prompt) (*models.Prompt, error) {
-	if len(prompts) == 0 {
-		return nil, fmt.Errorf("no prompts available")
+	selected, err := PickPrompts(prompts, PickerOptions{})
+	if err != nil {
+		return nil, err
 	}
+	return &selected[0], nil
+}
 
-	templates := &promptui.SelectTemplates{
-		Label:    "{{ . }}",
-		Active:   "▸ {{ .ID | cyan }} {{ if .Name }}[{{ .Name | green }}] {{ end }}({{ .Type | yellow }}) {{ .Content | truncate 60 }}",
-		Inactive: "  {{ .ID | cyan }} {{ if .Name }}[{{ .Name | green }}] {{ end }}({{ .Type | yellow }}) {{ .Content | truncate 60 }}",
-		Selected: "✓ Selected: {{ .ID | cyan }}{{ if .Name }} [{{ .Name }}]{{ end }}",
-		Details: `
---------- Details ----------
-ID:       {{ .ID }}
-{{ if .Name }}Name:     {{ .Name }}
-{{ end }}Type:     {{ .Type }}
-Project:  {{ .Project }}
-Created:  {{ .CreatedAt.Format "2006-01-02 15:04" }}
-Tags:     {{ joinTags .Tags }}
-Content:
-{{ .Content }}`,
-	}
+// SelectVariant asks which variant of a prompt to use.
+// It returns an empty string when the default content is chosen.
+func SelectVariant(p *models.Prompt) (string, error) {
+	items := append([]string{"(default)"}, p.VariantNames()...)
+	label := fmt.Sprintf("Select variant of %s", p.ID)
 
-	// Custom function map for templates
-	templates.FuncMap = promptui.FuncMap
-	templates.FuncMap["truncate"] = func(length int, s string) string {
-		if len(s) <= length {
-			return s
-		}
-		return s[:length] + "..."
+	var i int
+	var err error
+	if IsInteractive() {
+		i, err = selectString(label, items)
+	} else {
+		i, err = selectStringFromList(label, items)
 	}
-	templates.FuncMap["joinTags"] = func(tags []string) string {
-		if len(tags) == 0 {
-			return "(none)"
-		}
-		return strings.Join(tags, ", ")
-	}
-
-	searcher := func(input string, index int) bool {
-		prompt := prompts[index]
-		input = strings.Replace(strings.ToLower(input), " ", "", -1)
-
-		// Search in ID, name, type, content, and tags
-		searchStr := strings.ToLower(prompt.ID + prompt.Name + prompt.Type + prompt.Content + strings.Join(prompt.Tags, " "))
-		searchStr = strings.Replace(searchStr, " ", "", -1)
-		return strings.Contains(searchStr, input)
+	if err != nil {
+		return "", err
 	}
 
-	prompt := promptui.Select{
-		Label:     "Select Prompt",
-		Items:     prompts,
-		Templates: templates,
-		Size:      10,
-		Searcher:  searcher,
+	if i == 0 {
+		return "", nil
 	}
+	return items[i], nil
+}
 
-	i, _, err := prompt.Run()
+// selectString shows a plain list of items on the terminal and returns the
+// index of the chosen one
+func selectString(label string, items []string) (int, error) {
+	t, err := openTerminal()
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
+	defer t.close()
+
+	cursor := 0
+	for {
+		lines := []string{"? " + bold(label)}
+		for i, item := range items {
+			if i == cursor {
+				lines = append(lines, "▸ "+cyan(item))
+			} else {
+				lines = append(lines, "  "+item)
+			}
+		}
+		lines = append(lines, faint("↑/↓ move • enter confirm • esc cancel"))
+		t.draw(lines)
 
-	return &prompts[i], nil
+		k, err := t.readKey()
+		if err != nil {
+			t.clear()
+			return 0, err
+		}
+		switch {
+		case k.key == keyCtrlC || k.key == keyEscape || (k.key == keyRune && k.char == 'q'):
+			t.clear()
+			return 0, ErrCancelled
+		case k.key == keyUp || (k.key == keyRune && k.char == 'k'):
+			cursor = max(cursor-1, 0)
+		case k.key == keyDown || k.key == keyTab || (k.key == keyRune && k.char == 'j'):
+			cursor = min(cursor+1, len(items)-1)
+		case k.key == keyEnter:
+			t.clear()
+			return cursor, nil
+		}
+	}
 }
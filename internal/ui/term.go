@@ -0,0 +1,276 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chzyer/readline"
+)
+
+// key identifies a special key read from the terminal
+type key int
+
+const (
+	keyRune key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyTab
+	keyCtrlC
+	keyUnknown
+)
+
+// keyPress is a single decoded key press
+type keyPress struct {
+	key  key
+	char rune // set for keyRune
+}
+
+// terminal is a raw-mode terminal session used by the custom pickers
+type terminal struct {
+	fd    int
+	state *readline.State
+	out   io.Writer
+	lines int    // lines drawn by the last frame, for inline redraws
+	buf   []byte // bytes read but not yet decoded, e.g. from a paste
+
+	fullscreen bool // drawing on the alternate screen
+}
+
+// openTerminal switches stdin to raw mode and hides the cursor
+func openTerminal() (*terminal, error) {
+	fd := int(os.Stdin.Fd())
+	state, err := readline.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up terminal: %w", err)
+	}
+
+	t := &terminal{fd: fd, state: state, out: os.Stdout}
+	fmt.Fprint(t.out, "\x1b[?25l")
+	return t, nil
+}
+
+// close restores the terminal to its previous mode and shows the cursor
+func (t *terminal) close() {
+	fmt.Fprint(t.out, "\x1b[?25h")
+	readline.Restore(t.fd, t.state)
+}
+
+// suspend temporarily leaves raw mode, e.g. to run an editor
+func (t *terminal) suspend() {
+	if t.fullscreen {
+		fmt.Fprint(t.out, "\x1b[?1049l")
+	} else {
+		t.clear()
+	}
+	fmt.Fprint(t.out, "\x1b[?25h")
+	readline.Restore(t.fd, t.state)
+}
+
+// resume re-enters raw mode after suspend
+func (t *terminal) resume() error {
+	state, err := readline.MakeRaw(t.fd)
+	if err != nil {
+		return fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	t.state = state
+	fmt.Fprint(t.out, "\x1b[?25l")
+	if t.fullscreen {
+		fmt.Fprint(t.out, "\x1b[?1049h")
+	}
+	return nil
+}
+
+// enterFullscreen switches to the alternate screen, leaving the shell's
+// scrollback untouched
+func (t *terminal) enterFullscreen() {
+	fmt.Fprint(t.out, "\x1b[?1049h\x1b[H")
+	t.fullscreen = true
+}
+
+// exitFullscreen returns to the normal screen
+func (t *terminal) exitFullscreen() {
+	fmt.Fprint(t.out, "\x1b[?1049l")
+	t.fullscreen = false
+}
+
+// drawScreen redraws the whole screen from the top-left corner
+func (t *terminal) drawScreen(lines []string) {
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+		if i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString("\x1b[J")
+	fmt.Fprint(t.out, b.String())
+}
+
+// size returns the terminal width and height, with a sensible fallback
+func (t *terminal) size() (int, int) {
+	width, height, err := readline.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// draw replaces the previously drawn frame with the given lines
+func (t *terminal) draw(lines []string) {
+	var b strings.Builder
+	if t.lines > 1 {
+		fmt.Fprintf(&b, "\x1b[%dA", t.lines-1)
+	}
+	b.WriteString("\r\x1b[J")
+	b.WriteString(strings.Join(lines, "\r\n"))
+	fmt.Fprint(t.out, b.String())
+	t.lines = len(lines)
+}
+
+// clear erases the previously drawn frame
+func (t *terminal) clear() {
+	if t.lines == 0 {
+		return
+	}
+	t.draw([]string{""})
+	t.lines = 0
+}
+
+// readKey blocks until a key is pressed and decodes it. Several keys
+// arriving in one read, as when pasting, are returned one at a time.
+func (t *terminal) readKey() (keyPress, error) {
+	if len(t.buf) == 0 {
+		buf := make([]byte, 256)
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return keyPress{}, err
+		}
+		t.buf = buf[:n]
+	}
+
+	k, size := decodeKey(t.buf)
+	t.buf = t.buf[size:]
+	return k, nil
+}
+
+// decodeKey decodes the first key press in b and reports how many bytes it used
+func decodeKey(b []byte) (keyPress, int) {
+	if len(b) == 0 {
+		return keyPress{key: keyUnknown}, 0
+	}
+
+	switch b[0] {
+	case '\r', '\n':
+		return keyPress{key: keyEnter}, 1
+	case 3:
+		return keyPress{key: keyCtrlC}, 1
+	case 9:
+		return keyPress{key: keyTab}, 1
+	case 127, 8:
+		return keyPress{key: keyBackspace}, 1
+	case 14: // Ctrl-N
+		return keyPress{key: keyDown}, 1
+	case 16: // Ctrl-P
+		return keyPress{key: keyUp}, 1
+	case 27:
+		size := escapeLength(b)
+		if size == 1 {
+			return keyPress{key: keyEscape}, 1
+		}
+		return decodeEscape(string(b[1:size])), size
+	}
+
+	r, size := utf8.DecodeRune(b)
+	if r == utf8.RuneError || r < 32 {
+		return keyPress{key: keyUnknown}, size
+	}
+	return keyPress{key: keyRune, char: r}, size
+}
+
+// escapeLength returns the length of the escape sequence at the start of b:
+// ESC [ params final, ESC O x, or a lone ESC
+func escapeLength(b []byte) int {
+	if len(b) < 3 {
+		return 1
+	}
+	switch b[1] {
+	case 'O':
+		return 3
+	case '[':
+		for i := 2; i < len(b); i++ {
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	}
+	return 1
+}
+
+// decodeEscape decodes the part of an escape sequence after ESC
+func decodeEscape(seq string) keyPress {
+	switch seq {
+	case "[A", "OA":
+		return keyPress{key: keyUp}
+	case "[B", "OB":
+		return keyPress{key: keyDown}
+	case "[C", "OC":
+		return keyPress{key: keyRight}
+	case "[D", "OD":
+		return keyPress{key: keyLeft}
+	case "[5~":
+		return keyPress{key: keyPageUp}
+	case "[6~":
+		return keyPress{key: keyPageDown}
+	case "[H", "OH", "[1~", "[7~":
+		return keyPress{key: keyHome}
+	case "[F", "OF", "[4~", "[8~":
+		return keyPress{key: keyEnd}
+	}
+	return keyPress{key: keyUnknown}
+}
+
+// ANSI styling helpers.
+// The colors are dropped when colors are turned off; bold, faint and inverse
+// text are kept so that the cursor stays visible.
+func cyan(s string) string   { return color("36", s) }
+func green(s string) string  { return color("32", s) }
+func yellow(s string) string { return color("33", s) }
+func faint(s string) string  { return "\x1b[2m" + s + "\x1b[0m" }
+func bold(s string) string   { return "\x1b[1m" + s + "\x1b[0m" }
+func invert(s string) string { return "\x1b[7m" + s + "\x1b[0m" }
+
+// colorCodes are the ANSI codes of the colors a prompt type can have
+var colorCodes = map[string]string{
+	"black": "30", "red": "31", "green": "32", "yellow": "33",
+	"blue": "34", "magenta": "35", "cyan": "36", "white": "37",
+}
+
+// typeColor shows a prompt type in the color registered for it, yellow by default
+func typeColor(name string) string {
+	if code, ok := colorCodes[typeColors[strings.ToLower(name)]]; ok {
+		return color(code, name)
+	}
+	return yellow(name)
+}
+
+// color wraps s in the given ANSI color, if colors are on
+func color(code, s string) string {
+	if !useColor {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
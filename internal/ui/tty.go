@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chzyer/readline"
+	"github.com/sunny/pmt/internal/models"
+	"github.com/sunny/pmt/internal/utils"
+)
+
+// nonInteractive forces the plain-text fallbacks even on a terminal
+var nonInteractive = os.Getenv("PMT_NO_INTERACTIVE") != ""
+
+// stdin is shared so that consecutive questions don't lose buffered input
+var stdin = bufio.NewReader(os.Stdin)
+
+// SetNonInteractive forces (or stops forcing) the non-interactive fallbacks
+func SetNonInteractive(v bool) {
+	nonInteractive = v
+}
+
+// Display settings, set from the user's configuration
+var (
+	useColor   = true
+	pageSize   = 10
+	dateFormat = "2006-01-02 15:04"
+)
+
+// SetColor turns the colors of selectors on or off
+func SetColor(v bool) {
+	useColor = v
+}
+
+// SetPageSize sets how many prompts selectors show at once
+func SetPageSize(n int) {
+	pageSize = n
+}
+
+// typeColors maps prompt types to the colors registered for them
+var typeColors = map[string]string{}
+
+// SetTypeColors sets the colors prompt types are shown in, by type name
+func SetTypeColors(colors map[string]string) {
+	typeColors = colors
+}
+
+// SetDateFormat sets the Go time layout used to show dates
+func SetDateFormat(layout string) {
+	dateFormat = layout
+}
+
+// FormatDate formats a time with the configured date format
+func FormatDate(t time.Time) string {
+	return t.Format(dateFormat)
+}
+
+// IsInteractive reports whether full-screen selectors can be used:
+// both stdin and stdout must be terminals and interactivity must not be disabled
+func IsInteractive() bool {
+	if nonInteractive {
+		return false
+	}
+	return readline.IsTerminal(int(os.Stdin.Fd())) && readline.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// Confirm asks a yes/no question on the terminal. Without an interactive
+// terminal it fails instead of assuming an answer or reading one from piped
+// input, so callers must be told to skip it with --force.
+func Confirm(question string) (bool, error) {
+	if !IsInteractive() {
+		return false, fmt.Errorf("confirmation required but not running interactively: %s (use --force to skip it)", question)
+	}
+	fmt.Fprintf(os.Stderr, "%s\nType 'yes' to confirm: ", question)
+
+	response, err := readLine()
+	if err != nil {
+		return false, fmt.Errorf("confirmation required but no input is available (use --force to skip it): %w", err)
+	}
+
+	response = strings.ToLower(response)
+	return response == "yes" || response == "y", nil
+}
+
+// selectFromList is the non-interactive selector: it prints a numbered list to
+// stderr and reads a number or ID from stdin. When there is nothing to read it
+// fails with the list of candidate IDs, so scripts can pass one explicitly.
+func selectFromList(prompts []models.Prompt) (*models.Prompt, error) {
+	printList(prompts)
+	fmt.Fprintf(os.Stderr, "Select a prompt [1-%d]: ", len(prompts))
+
+	choice, err := readLine()
+	if err != nil || choice == "" {
+		return nil, fmt.Errorf("no interactive terminal and no selection on stdin; pass one of these IDs: %s", joinIDs(prompts))
+	}
+
+	i, err := matchChoice(prompts, choice)
+	if err != nil {
+		return nil, err
+	}
+	return &prompts[i], nil
+}
+
+// printList prints prompts as a numbered list to stderr
+func printList(prompts []models.Prompt) {
+	for i, p := range prompts {
+		name := ""
+		if p.Name != "" {
+			name = "[" + p.Name + "] "
+		}
+		fmt.Fprintf(os.Stderr, "%3d) %s %s(%s) %s\n", i+1, p.ID, name, p.Type, oneLine(p.Text(), 60))
+	}
+}
+
+// matchChoice returns the index of the prompt chosen by its number in the
+// list or by a unique ID prefix
+func matchChoice(prompts []models.Prompt, choice string) (int, error) {
+	if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(prompts) {
+		return n - 1, nil
+	}
+
+	match := -1
+	var ids []string
+	for i := range prompts {
+		if utils.MatchIDPrefix(prompts[i].ID, choice) {
+			match = i
+			ids = append(ids, prompts[i].ID)
+		}
+	}
+	switch {
+	case len(ids) > 1:
+		return 0, fmt.Errorf("ambiguous ID %s: matches %s", choice, strings.Join(ids, ", "))
+	case match < 0:
+		return 0, fmt.Errorf("invalid selection %s: must be a number from 1 to %d or an ID", choice, len(prompts))
+	}
+	return match, nil
+}
+
+// joinIDs lists the IDs of prompts for messages
+func joinIDs(prompts []models.Prompt) string {
+	ids := make([]string, len(prompts))
+	for i, p := range prompts {
+		ids[i] = p.ID
+	}
+	return strings.Join(ids, ", ")
+}
+
+// selectStringFromList is the non-interactive variant of a plain string selector
+func selectStringFromList(label string, items []string) (int, error) {
+	for i, item := range items {
+		fmt.Fprintf(os.Stderr, "%3d) %s\n", i+1, item)
+	}
+	fmt.Fprintf(os.Stderr, "%s [1-%d]: ", label, len(items))
+
+	choice, err := readLine()
+	if err != nil || choice == "" {
+		return 0, fmt.Errorf("no interactive terminal and no selection on stdin; choose one of: %s", strings.Join(items, ", "))
+	}
+
+	n, err := strconv.Atoi(choice)
+	if err == nil && n >= 1 && n <= len(items) {
+		return n - 1, nil
+	}
+	for i, item := range items {
+		if item == choice {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid selection: %s", choice)
+}
+
+// readLine reads one trimmed line from stdin. A final line without a newline is accepted.
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err != nil && !(err == io.EOF && line != "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// oneLine collapses whitespace and truncates s for single-line display
+func oneLine(s string, maxLen int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxLen {
+		return string(runes[:maxLen]) + "..."
+	}
+	return s
+}
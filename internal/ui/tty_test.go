@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sunny/pmt/internal/models"
+)
+
+func TestOneLine(t *testing.T) {
+	tests := []struct {
+		s      string
+		maxLen int
+		want   string
+	}{
+		{"short", 10, "short"},
+		{"collapse\n  all\twhitespace", 30, "collapse all whitespace"},
+		{"abcdef", 3, "abc..."},
+		{"héllo wörld", 4, "héll..."},
+		{"日本語のプロンプト", 3, "日本語..."},
+		{"🙂🙂🙂", 3, "🙂🙂🙂"},
+	}
+	for _, tt := range tests {
+		got := oneLine(tt.s, tt.maxLen)
+		if got != tt.want {
+			t.Errorf("oneLine(%q, %d) = %q, want %q", tt.s, tt.maxLen, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("oneLine(%q, %d) = %q is not valid UTF-8", tt.s, tt.maxLen, got)
+		}
+	}
+}
+
+func TestDetailLineTruncatesByRunes(t *testing.T) {
+	p := &models.Prompt{
+		Project:   "github.com/org/api",
+		Context:   "バックエンド/認証",
+		Tags:      []string{"größe", "日本"},
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	full := detailLine(p, 1000)
+
+	for width := 4; width < utf8.RuneCountInString(full); width++ {
+		got := detailLine(p, width)
+		if !utf8.ValidString(got) {
+			t.Fatalf("detailLine(width %d) = %q is not valid UTF-8", width, got)
+		}
+		if n := utf8.RuneCountInString(got); n != width {
+			t.Fatalf("detailLine(width %d) has %d runes: %q", width, n, got)
+		}
+		if !strings.HasSuffix(got, "...") {
+			t.Fatalf("detailLine(width %d) = %q, want a ... suffix", width, got)
+		}
+	}
+}
@@ -1,26 +1,216 @@
 package utils
 
 import (
+	"fmt"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
-// DetectGitProject returns the current git project name, or "no-project" if not in a git repo
+// Project identifies a git project
+type Project struct {
+	ID   string // stable key: normalized origin URL, or "commit:" + root commit hash
+	Name string // display name: the toplevel directory's base name
+}
+
+// NoProject is the project of prompts saved outside a git repository
+const NoProject = "no-project"
+
+// DetectGitProject returns the stable ID of the current git project, or
+// "no-project" if not in a git repo
 func DetectGitProject() string {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
+	return DetectProject().ID
+}
+
+// DetectProject identifies the current git project. The ID is the normalized
+// origin remote URL, so clones in differently named folders share their
+// prompts; without a remote it falls back to the root commit hash, and in a
+// repository without commits to the directory name. The result is computed
+// once per process.
+func DetectProject() Project {
+	return detectProject()
+}
+
+var detectProject = sync.OnceValue(func() Project {
+	root, err := GitRoot()
 	if err != nil {
-		return "no-project"
+		return Project{ID: NoProject, Name: NoProject}
 	}
 
 	// Get the base name of the project directory
-	projectPath := strings.TrimSpace(string(output))
-	projectName := filepath.Base(projectPath)
+	name := filepath.Base(root)
+	if name == "" || name == "." {
+		return Project{ID: NoProject, Name: NoProject}
+	}
+
+	if output, err := exec.Command("git", "config", "--get", "remote.origin.url").Output(); err == nil {
+		if id := NormalizeRemoteURL(strings.TrimSpace(string(output))); id != "" {
+			return Project{ID: id, Name: name}
+		}
+	}
+
+	if output, err := exec.Command("git", "rev-list", "--max-parents=0", "HEAD").Output(); err == nil {
+		// A history with several roots lists them all; the last one is the oldest
+		roots := strings.Fields(string(output))
+		if len(roots) > 0 {
+			return Project{ID: "commit:" + roots[len(roots)-1], Name: name}
+		}
+	}
+
+	return Project{ID: name, Name: name}
+})
+
+// NormalizeRemoteURL turns the different spellings of a git remote into one
+// key, e.g. "git@github.com:Org/api.git" and "https://github.com/org/api"
+// both become "github.com/org/api"
+func NormalizeRemoteURL(url string) string {
+	url = strings.TrimSpace(url)
 
-	if projectName == "" || projectName == "." {
-		return "no-project"
+	// Drop the scheme
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	} else if i := strings.Index(url, ":"); i >= 0 && !strings.Contains(url[:i], "/") {
+		// scp-like syntax: [user@]host:path
+		url = url[:i] + "/" + url[i+1:]
 	}
 
-	return projectName
+	// Drop credentials and ports
+	if i := strings.Index(url, "@"); i >= 0 && i < strings.Index(url+"/", "/") {
+		url = url[i+1:]
+	}
+	if slash := strings.Index(url, "/"); slash >= 0 {
+		host, path := url[:slash], url[slash:]
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		url = host + path
+	}
+
+	url = strings.TrimRight(url, "/")
+	url = strings.TrimSuffix(url, ".git")
+	return strings.ToLower(strings.TrimRight(url, "/"))
+}
+
+// GitRoot returns the absolute path of the current git toplevel directory.
+// pmt never changes directory, so the lookup runs once per process.
+func GitRoot() (string, error) {
+	return gitRoot()
+}
+
+var gitRoot = sync.OnceValues(func() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+})
+
+// GitBranch returns the name of the checked out branch, or "" outside a
+// repository or on a detached HEAD
+func GitBranch() string {
+	output, err := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// GitHead returns the hash of the HEAD commit, or "" if there is none
+func GitHead() string {
+	output, err := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// GitDefaultBranch returns the branch others are merged into: the remote's
+// HEAD (e.g. "origin/main"), or else a local main or master
+func GitDefaultBranch() (string, error) {
+	if output, err := exec.Command("git", "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD").Output(); err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+	for _, name := range []string{"main", "master"} {
+		if exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+name).Run() == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("could not find the default branch")
+}
+
+// GitBranches returns the short names of the local branches and the branches
+// of origin without their "origin/" prefix, each with the commit it points
+// at; a local branch wins over origin's of the same name. With merged set,
+// only branches merged into it are returned.
+func GitBranches(merged string) (map[string]string, error) {
+	args := []string{"for-each-ref", "--format=%(refname) %(objectname)"}
+	if merged != "" {
+		args = append(args, "--merged", merged)
+	}
+	args = append(args, "refs/heads", "refs/remotes/origin")
+
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		ref, commit, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(ref, "refs/heads/"):
+			branches[strings.TrimPrefix(ref, "refs/heads/")] = commit
+		case strings.HasPrefix(ref, "refs/remotes/origin/") && ref != "refs/remotes/origin/HEAD":
+			name := strings.TrimPrefix(ref, "refs/remotes/origin/")
+			if _, ok := branches[name]; !ok {
+				branches[name] = commit
+			}
+		}
+	}
+	return branches, nil
+}
+
+// GitPrefix returns the current directory relative to the git toplevel as a
+// slash-separated path, e.g. "services/billing"; "" at the toplevel itself
+func GitPrefix() (string, error) {
+	return gitPrefix()
+}
+
+var gitPrefix = sync.OnceValues(func() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSpace(string(output)), "/"), nil
+})
+
+// GitListFiles returns the tracked and untracked-but-not-ignored files under root,
+// as slash-separated paths relative to root. Files matched by .gitignore are excluded.
+func GitListFiles(root string) ([]string, error) {
+	cmd := exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard", "-z")
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, f := range strings.Split(string(output), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// GitIgnored reports whether git ignores the file at path, relative to root.
+// Outside a repository nothing is ignored.
+func GitIgnored(root, path string) bool {
+	cmd := exec.Command("git", "check-ignore", "--quiet", "--", path)
+	cmd.Dir = root
+	return cmd.Run() == nil
 }
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a repository with a commit on main in a temporary
+// directory and makes it the working directory for the test
+func gitRepo(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	git(t, "init", "-q", "-b", "main")
+	git(t, "commit", "-q", "--allow-empty", "-m", "initial")
+}
+
+// git runs a git command in the working directory and returns its output
+func git(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestGitBranches(t *testing.T) {
+	gitRepo(t)
+
+	// merged: a branch with a commit that was merged into main
+	git(t, "checkout", "-q", "-b", "merged")
+	git(t, "commit", "-q", "--allow-empty", "-m", "merged work")
+	git(t, "checkout", "-q", "main")
+	git(t, "merge", "-q", "--no-ff", "-m", "merge", "merged")
+
+	// open: a branch with a commit that is not on main
+	git(t, "checkout", "-q", "-b", "open")
+	git(t, "commit", "-q", "--allow-empty", "-m", "open work")
+	git(t, "checkout", "-q", "main")
+
+	// fresh: a new branch without commits of its own
+	git(t, "branch", "fresh")
+
+	heads := map[string]string{
+		"main":   git(t, "rev-parse", "main"),
+		"merged": git(t, "rev-parse", "merged"),
+		"open":   git(t, "rev-parse", "open"),
+		"fresh":  git(t, "rev-parse", "fresh"),
+	}
+
+	tests := []struct {
+		merged string
+		want   []string
+	}{
+		{"", []string{"main", "merged", "open", "fresh"}},
+		{"main", []string{"main", "merged", "fresh"}},
+	}
+
+	for _, tt := range tests {
+		got, err := GitBranches(tt.merged)
+		if err != nil {
+			t.Fatalf("GitBranches(%q): %v", tt.merged, err)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("GitBranches(%q) = %v, want %v", tt.merged, got, tt.want)
+			continue
+		}
+		for _, name := range tt.want {
+			if got[name] != heads[name] {
+				t.Errorf("GitBranches(%q)[%s] = %q, want %q", tt.merged, name, got[name], heads[name])
+			}
+		}
+	}
+}
+
+func TestNormalizeRemoteURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"git@github.com:Org/api.git", "github.com/org/api"},
+		{"https://github.com/org/api", "github.com/org/api"},
+		{"https://github.com/org/api/", "github.com/org/api"},
+		{"ssh://git@github.com/org/api.git", "github.com/org/api"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeRemoteURL(tt.url); got != tt.want {
+			t.Errorf("NormalizeRemoteURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}